@@ -0,0 +1,209 @@
+package circuits
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+
+	tbls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/twistededwards"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/bandersnatch"
+	jubjub "github.com/consensys/gnark-crypto/ecc/bls12-381/twistededwards"
+	tbls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/twistededwards"
+	tbls24317 "github.com/consensys/gnark-crypto/ecc/bls24-317/twistededwards"
+	tbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	tbw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/twistededwards"
+	tbw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/twistededwards"
+)
+
+// The emulated.FieldParams below are the scalar fields, i.e. the prime
+// subgroup orders, of the twisted Edwards curves of gnark-crypto. They allow
+// to check scalar decompositions mod r in-circuit.
+
+type fourLimbPrimeField struct{}
+
+func (fourLimbPrimeField) NbLimbs() uint     { return 4 }
+func (fourLimbPrimeField) BitsPerLimb() uint { return 64 }
+func (fourLimbPrimeField) IsPrime() bool     { return true }
+
+type fiveLimbPrimeField struct{}
+
+func (fiveLimbPrimeField) NbLimbs() uint     { return 5 }
+func (fiveLimbPrimeField) BitsPerLimb() uint { return 64 }
+func (fiveLimbPrimeField) IsPrime() bool     { return true }
+
+type sixLimbPrimeField struct{}
+
+func (sixLimbPrimeField) NbLimbs() uint     { return 6 }
+func (sixLimbPrimeField) BitsPerLimb() uint { return 64 }
+func (sixLimbPrimeField) IsPrime() bool     { return true }
+
+// BabyJubjubFr provides type parametrization for field emulation:
+//   - limbs: 4
+//   - limb width: 64 bits
+//
+// The prime modulus for type parametrisation is:
+//
+//	0x60c89ce5c263405370a08b6d0302b0bab3eedb83920ee0a677297dc392126f1 (base 16)
+//	2736030358979909402780800718157159386076813972158567259200215660948447373041 (base 10)
+//
+// This is the scalar field of the BabyJubjub curve (over BN254).
+type BabyJubjubFr struct{ fourLimbPrimeField }
+
+func (fp BabyJubjubFr) Modulus() *big.Int {
+	val := tbn254.GetEdwardsCurve().Order
+	return &val
+}
+
+// EdBLS12377Fr provides type parametrization for field emulation:
+//   - limbs: 4
+//   - limb width: 64 bits
+//
+// The prime modulus for type parametrisation is:
+//
+//	0x4aad957a68b2955982d1347970dec005293a3afc43c8afeb95aee9ac33fd9ff (base 16)
+//	2111115437357092606062206234695386632838870926408408195193685246394721360383 (base 10)
+//
+// This is the scalar field of the twisted Edwards curve over BLS12-377.
+type EdBLS12377Fr struct{ fourLimbPrimeField }
+
+func (fp EdBLS12377Fr) Modulus() *big.Int {
+	val := tbls12377.GetEdwardsCurve().Order
+	return &val
+}
+
+// JubjubFr provides type parametrization for field emulation:
+//   - limbs: 4
+//   - limb width: 64 bits
+//
+// The prime modulus for type parametrisation is:
+//
+//	0xe7db4ea6533afa906673b0101343b00a6682093ccc81082d0970e5ed6f72cb7 (base 16)
+//	6554484396890773809930967563523245729705921265872317281365359162392183254199 (base 10)
+//
+// This is the scalar field of the Jubjub curve.
+type JubjubFr struct{ fourLimbPrimeField }
+
+func (fp JubjubFr) Modulus() *big.Int {
+	val := jubjub.GetEdwardsCurve().Order
+	return &val
+}
+
+// BandersnatchFr provides type parametrization for field emulation:
+//   - limbs: 4
+//   - limb width: 64 bits
+//
+// The prime modulus for type parametrisation is:
+//
+//	0x1cfb69d4ca675f520cce760202687600ff8f87007419047174fd06b52876e7e1 (base 16)
+//	13108968793781547619861935127046491459309155893440570251786403306729687672801 (base 10)
+//
+// This is the scalar field of the Bandersnatch curve.
+type BandersnatchFr struct{ fourLimbPrimeField }
+
+func (fp BandersnatchFr) Modulus() *big.Int {
+	val := bandersnatch.GetEdwardsCurve().Order
+	return &val
+}
+
+// EdBW6761Fr provides type parametrization for field emulation:
+//   - limbs: 6
+//   - limb width: 64 bits
+//
+// The prime modulus for type parametrisation is:
+//
+//	0x35c748c2f8a21d58c760b80d94292763445b3e601ea271e1d75fe7d6eeb84234066d10f5d893814103486497d95295 (base 16)
+//	32333053251621136751331591711861691692049189094364332567435817881934511297123972799646723302813083835942624121493 (base 10)
+//
+// This is the scalar field of the twisted Edwards curve over BW6-761.
+type EdBW6761Fr struct{ sixLimbPrimeField }
+
+func (fp EdBW6761Fr) Modulus() *big.Int {
+	val := tbw6761.GetEdwardsCurve().Order
+	return &val
+}
+
+// EdBW6633Fr provides type parametrization for field emulation:
+//   - limbs: 5
+//   - limb width: 64 bits
+//
+// The prime modulus for type parametrisation is:
+//
+//	0x98474056b0daca1a7ee9317d2f8bd5fbd83a035540306f0f328ceee31a83eb95587428a793f7af (base 16)
+//	4963142838689179791878211236301121218116687802119716497817028544854034649070444389864454748079 (base 10)
+//
+// This is the scalar field of the twisted Edwards curve over BW6-633.
+type EdBW6633Fr struct{ fiveLimbPrimeField }
+
+func (fp EdBW6633Fr) Modulus() *big.Int {
+	val := tbw6633.GetEdwardsCurve().Order
+	return &val
+}
+
+// EdBLS24315Fr provides type parametrization for field emulation:
+//   - limbs: 4
+//   - limb width: 64 bits
+//
+// The prime modulus for type parametrisation is:
+//
+//	0x32dbd584953b42564bf8fd939f24f53138f389f67beda7e5558abe965b8f281 (base 16)
+//	1437753473921907580703509300571927811987591765799164617677716990775193563777 (base 10)
+//
+// This is the scalar field of the twisted Edwards curve over BLS24-315.
+type EdBLS24315Fr struct{ fourLimbPrimeField }
+
+func (fp EdBLS24315Fr) Modulus() *big.Int {
+	val := tbls24315.GetEdwardsCurve().Order
+	return &val
+}
+
+// EdBLS24317Fr provides type parametrization for field emulation:
+//   - limbs: 4
+//   - limb width: 64 bits
+//
+// The prime modulus for type parametrisation is:
+//
+//	0x887f22fd4d1b5f85a1612fe51b079a91ffa0e80551b97b458b17ab6a69cb571 (base 16)
+//	3858698654557105525567273719690987823069521430163883173133245580997415449969 (base 10)
+//
+// This is the scalar field of the twisted Edwards curve over BLS24-317.
+type EdBLS24317Fr struct{ fourLimbPrimeField }
+
+func (fp EdBLS24317Fr) Modulus() *big.Int {
+	val := tbls24317.GetEdwardsCurve().Order
+	return &val
+}
+
+// ScalarToEmulated returns the native scalar s as an element of the emulated
+// field T. The limbs are given by the decompose hint, range-checked to the
+// widths of the limbs of r and recomposed to s itself, so that the element is
+// bound to s. It is not reduced mod r: s must be smaller than 2^|r|, and the
+// recomposition is below 2^|r| so it does not wrap around the native field.
+//
+// It is a standalone helper: the checkHalfGCD* checks do not use it and build
+// the emulated scalar from its bits with FromBits instead.
+func ScalarToEmulated[T emulated.FieldParams](api frontend.API, s frontend.Variable) *emulated.Element[T] {
+	var fp T
+	if fp.Modulus().BitLen() >= api.Compiler().FieldBitLen() {
+		panic("the emulated modulus does not fit in the native field")
+	}
+	f, err := emulated.NewField[T](api)
+	if err != nil {
+		panic(err)
+	}
+	nbLimbs, width := fp.NbLimbs(), fp.BitsPerLimb()
+	limbs, err := api.Compiler().NewHint(decompose, int(nbLimbs), nbLimbs, width, s)
+	if err != nil {
+		panic(err)
+	}
+	// NewElement range-checks the limbs, the most significant one to the
+	// width of the modulus.
+	e := f.NewElement(limbs)
+
+	recomposed := frontend.Variable(0)
+	for i := len(limbs) - 1; i >= 0; i-- {
+		recomposed = api.Add(api.Mul(recomposed, new(big.Int).Lsh(big.NewInt(1), width)), limbs[i])
+	}
+	api.AssertIsEqual(recomposed, s)
+	return e
+}
@@ -0,0 +1,105 @@
+package circuits
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+)
+
+type scalarToEmulated[T emulated.FieldParams] struct {
+	S frontend.Variable
+	R emulated.Element[T]
+}
+
+func (circuit *scalarToEmulated[T]) Define(api frontend.API) error {
+	f, err := emulated.NewField[T](api)
+	if err != nil {
+		return err
+	}
+	f.AssertIsEqual(ScalarToEmulated[T](api, circuit.S), &circuit.R)
+	return nil
+}
+
+func testScalarToEmulated[T emulated.FieldParams](t *testing.T, field *big.Int) {
+	var fp T
+	r := fp.Modulus()
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(r.BitLen()))
+	s, _ := rand.Int(rand.Reader, bound)
+	for _, s := range []*big.Int{big.NewInt(0), new(big.Int).Set(r), new(big.Int).Sub(bound, big.NewInt(1)), s} {
+		assignment := &scalarToEmulated[T]{S: s, R: emulated.ValueOf[T](new(big.Int).Mod(s, r))}
+		if err := test.IsSolved(&scalarToEmulated[T]{}, assignment, field); err != nil {
+			t.Fatalf("s = %s: %v", s, err)
+		}
+	}
+}
+
+func TestScalarToEmulated(t *testing.T) {
+	testScalarToEmulated[BandersnatchFr](t, ecc.BLS12_381.ScalarField())
+	testScalarToEmulated[JubjubFr](t, ecc.BLS12_381.ScalarField())
+	testScalarToEmulated[BabyJubjubFr](t, ecc.BN254.ScalarField())
+	testScalarToEmulated[EdBW6633Fr](t, ecc.BW6_633.ScalarField())
+	testScalarToEmulated[EdBW6761Fr](t, ecc.BW6_761.ScalarField())
+}
+
+func TestScalarToEmulatedMaliciousHints(t *testing.T) {
+	field := ecc.BLS12_381.ScalarField()
+	r := BandersnatchFr{}.Modulus()
+	// s + r still fits in the width of r
+	s := big.NewInt(1 << 20)
+	sr := new(big.Int).Add(s, r)
+
+	limbsOf := func(x *big.Int) solver.Hint {
+		return func(mod *big.Int, inputs, outputs []*big.Int) error {
+			in := append([]*big.Int{}, inputs...)
+			in[2] = x
+			return decompose(mod, in, outputs)
+		}
+	}
+	// (s0 + 2^64, s1 - 1, s2, s3) recomposes to s but the first limb is too
+	// wide.
+	carried := func(mod *big.Int, inputs, outputs []*big.Int) error {
+		if err := decompose(mod, inputs, outputs); err != nil {
+			return err
+		}
+		outputs[0].Add(outputs[0], new(big.Int).Lsh(big.NewInt(1), 64))
+		outputs[1].Sub(outputs[1], big.NewInt(1))
+		return nil
+	}
+
+	ccs, err := frontend.Compile(field, scs.NewBuilder, &scalarToEmulated[BandersnatchFr]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name  string
+		s, r  *big.Int
+		hint  solver.Hint
+		valid bool
+	}{
+		{"honest", s, s, decompose, true},
+		{"limbs of s + r", s, sr, limbsOf(sr), false},
+		{"limbs of s + r claiming s", s, s, limbsOf(sr), false},
+		{"carried limbs", new(big.Int).Lsh(big.NewInt(1), 64), new(big.Int).Lsh(big.NewInt(1), 64), carried, false},
+		{"scalar wider than r", new(big.Int).Lsh(big.NewInt(1), 253), new(big.Int).Mod(new(big.Int).Lsh(big.NewInt(1), 253), r), decompose, false},
+	} {
+		assignment := &scalarToEmulated[BandersnatchFr]{S: tc.s, R: emulated.ValueOf[BandersnatchFr](tc.r)}
+		w, err := frontend.NewWitness(assignment, field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ccs.IsSolved(w, solver.OverrideHint(solver.GetHintID(decompose), tc.hint))
+		if tc.valid && err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !tc.valid && err == nil {
+			t.Fatalf("%s: the malicious hint was accepted", tc.name)
+		}
+	}
+}
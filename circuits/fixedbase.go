@@ -0,0 +1,151 @@
+package circuits
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	tEd "github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+)
+
+// Window widths of the fixed-base scalar multiplications.
+const (
+	fixedBaseWindow    = 2 // with Lookup2
+	fixedBaseWindowLog = 4 // with logup
+)
+
+// ScalarMulFixedBase computes the scalar multiplication [s]b on the twisted
+// Edwards curve id for a constant point b of the prime subgroup, e.g. the
+// generator of the curve or a Pedersen base.
+//
+// The multiples [d * 2^(2*j)]b, 0 ≤ d < 4, are precomputed at compile time for
+// every 2-bit window j of s, so that [s]b is the sum of one table entry per
+// window selected with Lookup2, without doublings nor hints.
+//
+// s can be any scalar. It panics if b is not a constant point of the prime
+// subgroup.
+func ScalarMulFixedBase(api frontend.API, base *tEd.Point, scalar frontend.Variable, id twistededwards.ID) *tEd.Point {
+	// get edwards curve curve
+	curve, err := tEd.NewEdCurve(api, id)
+	if err != nil {
+		return nil
+	}
+	bx, by := fixedBaseConstant(api, base)
+
+	// the canonical bits of s
+	b := api.ToBinary(scalar)
+	res := fixedBaseLookup2(api, curve, fixedBaseTables(id, bx, by, fixedBaseWindow, len(b)), b)
+	return &res
+}
+
+// ScalarMulFixedBaseLog is ScalarMulFixedBase with 4-bit windows and all the
+// tables in one logup lookup table.
+//
+// The 16 entries per window cost more than the additions they save, so that
+// ScalarMulFixedBase is cheaper for a single multiplication, in SCS as in R1CS.
+func ScalarMulFixedBaseLog(api frontend.API, base *tEd.Point, scalar frontend.Variable, id twistededwards.ID) *tEd.Point {
+	// get edwards curve curve
+	curve, err := tEd.NewEdCurve(api, id)
+	if err != nil {
+		return nil
+	}
+	bx, by := fixedBaseConstant(api, base)
+
+	// the canonical bits of s
+	b := api.ToBinary(scalar)
+	res := fixedBaseLogup(api, curve, fixedBaseTables(id, bx, by, fixedBaseWindowLog, len(b)), b)
+	return &res
+}
+
+// fixedBaseConstant returns the constant coordinates of the base. It panics if
+// they are not constants.
+func fixedBaseConstant(api frontend.API, base *tEd.Point) (x, y *big.Int) {
+	x, okX := api.Compiler().ConstantValue(base.X)
+	y, okY := api.Compiler().ConstantValue(base.Y)
+	if !okX || !okY {
+		panic("ScalarMulFixedBase: the base is not a constant point")
+	}
+	return x, y
+}
+
+// fixedBaseTables returns the tables [d * 2^(w*j)](x, y) for 0 ≤ d < 2^w and
+// the ⌈nbBits/w⌉ windows j. It panics if (x, y) is not in the prime subgroup
+// of the curve id.
+func fixedBaseTables(id twistededwards.ID, x, y *big.Int, w, nbBits int) [][][2]*big.Int {
+	params := mustCurveParams(id)
+	var ox, oy big.Int
+	if err := scalarMulNative(id, x, y, params.Order, &ox, &oy); err != nil {
+		panic(err)
+	}
+	if ox.Sign() != 0 || oy.Cmp(big.NewInt(1)) != 0 {
+		panic("ScalarMulFixedBase: the base is not in the prime subgroup")
+	}
+
+	tables := make([][][2]*big.Int, (nbBits+w-1)/w)
+	// g = [2^(w*j)](x, y)
+	g := [2]*big.Int{new(big.Int).Set(x), new(big.Int).Set(y)}
+	for j := range tables {
+		tables[j] = make([][2]*big.Int, 1<<w)
+		for d := range tables[j] {
+			tables[j][d] = [2]*big.Int{new(big.Int), new(big.Int)}
+			if err := scalarMulNative(id, g[0], g[1], big.NewInt(int64(d)), tables[j][d][0], tables[j][d][1]); err != nil {
+				panic(err)
+			}
+		}
+		if err := scalarMulNative(id, g[0], g[1], new(big.Int).Lsh(big.NewInt(1), uint(w)), g[0], g[1]); err != nil {
+			panic(err)
+		}
+	}
+	return tables
+}
+
+// fixedBaseLookup2 returns the sum of the entries of the 2-bit window tables
+// selected by the little-endian bits b, with Lookup2.
+func fixedBaseLookup2(api frontend.API, curve tEd.Curve, tables [][][2]*big.Int, b []frontend.Variable) tEd.Point {
+	var res tEd.Point
+	for j, t := range tables {
+		var tmp tEd.Point
+		if 2*j+1 < len(b) {
+			tmp.X = api.Lookup2(b[2*j], b[2*j+1], t[0][0], t[1][0], t[2][0], t[3][0])
+			tmp.Y = api.Lookup2(b[2*j], b[2*j+1], t[0][1], t[1][1], t[2][1], t[3][1])
+		} else {
+			tmp.X = api.Select(b[2*j], t[1][0], t[0][0])
+			tmp.Y = api.Select(b[2*j], t[1][1], t[0][1])
+		}
+		if j == 0 {
+			res = tmp
+		} else {
+			res = curve.Add(res, tmp)
+		}
+	}
+	return res
+}
+
+// fixedBaseLogup returns the sum of the entries of the window tables selected
+// by the little-endian bits b, with one logderivlookup table holding all the
+// tables, the table of the j-th window at offset j*len(tables[j]).
+func fixedBaseLogup(api frontend.API, curve tEd.Curve, tables [][][2]*big.Int, b []frontend.Variable) tEd.Point {
+	tblX := logderivlookup.New(api)
+	tblY := logderivlookup.New(api)
+	for _, t := range tables {
+		for _, e := range t {
+			tblX.Insert(e[0])
+			tblY.Insert(e[1])
+		}
+	}
+	size := len(tables[0])
+	digits := windows(api, b, fixedBaseWindowLog)
+	inds := make([]frontend.Variable, len(digits))
+	for j := range digits {
+		inds[j] = api.Add(digits[j], j*size)
+	}
+	xs := tblX.Lookup(inds...)
+	ys := tblY.Lookup(inds...)
+
+	res := tEd.Point{X: xs[0], Y: ys[0]}
+	for j := 1; j < len(xs); j++ {
+		res = curve.Add(res, tEd.Point{X: xs[j], Y: ys[j]})
+	}
+	return res
+}
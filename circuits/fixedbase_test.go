@@ -0,0 +1,155 @@
+package circuits
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/profile"
+	tEd "github.com/consensys/gnark/std/algebra/native/twistededwards"
+)
+
+type scalarMulFixedBase struct {
+	curveID twistededwards.ID
+	log     bool
+	base    [2]*big.Int
+	S       frontend.Variable
+	R       tEd.Point
+}
+
+func (circuit *scalarMulFixedBase) Define(api frontend.API) error {
+	base := tEd.Point{X: circuit.base[0], Y: circuit.base[1]}
+	var res *tEd.Point
+	if circuit.log {
+		res = ScalarMulFixedBaseLog(api, &base, circuit.S, circuit.curveID)
+	} else {
+		res = ScalarMulFixedBase(api, &base, circuit.S, circuit.curveID)
+	}
+	api.AssertIsEqual(res.X, circuit.R.X)
+	api.AssertIsEqual(res.Y, circuit.R.Y)
+
+	return nil
+}
+
+// curveGenerator returns the generator of the prime subgroup of the curve id.
+func curveGenerator(id twistededwards.ID) [2]*big.Int {
+	params, err := tEd.GetCurveParams(id)
+	if err != nil {
+		panic(err)
+	}
+	return [2]*big.Int{params.Base[0], params.Base[1]}
+}
+
+func TestScalarMulFixedBase(t *testing.T) {
+	for _, id := range curveIDs {
+		id := id
+		t.Run(curveNames[id], func(t *testing.T) {
+			t.Parallel()
+			params := mustCurveParams(id)
+			// [h]p is in the prime subgroup
+			other := curvePoints(id, 1)[0]
+			if err := scalarMulNative(id, other[0], other[1], params.Cofactor, other[0], other[1]); err != nil {
+				t.Fatal(err)
+			}
+			random, _ := rand.Int(rand.Reader, params.Order)
+			scalars := []*big.Int{
+				random,
+				big.NewInt(0),
+				big.NewInt(1),
+				new(big.Int).Sub(params.Order, big.NewInt(1)),
+				new(big.Int).Set(params.Order),
+				new(big.Int).Sub(params.Field, big.NewInt(1)),
+			}
+			torsion := smallOrderPoint(id)
+
+			for _, base := range [][2]*big.Int{curveGenerator(id), other} {
+				for _, builder := range []struct {
+					name string
+					new  frontend.NewBuilder
+					log  bool
+				}{
+					{"r1cs", r1cs.NewBuilder, false},
+					{"scs", scs.NewBuilder, false},
+					{"r1cs logup", r1cs.NewBuilder, true},
+					{"scs logup", scs.NewBuilder, true},
+				} {
+					ccs, err := frontend.Compile(params.Field, builder.new, &scalarMulFixedBase{curveID: id, log: builder.log, base: base})
+					if err != nil {
+						t.Fatal(err)
+					}
+					for _, s := range scalars {
+						var r [2]*big.Int
+						r[0], r[1] = new(big.Int), new(big.Int)
+						if err := scalarMulNative(id, base[0], base[1], s, r[0], r[1]); err != nil {
+							t.Fatal(err)
+						}
+						for _, expected := range []struct {
+							r     [2]*big.Int
+							valid bool
+						}{
+							{r, true},
+							{addNative(id, r, torsion), false},
+						} {
+							w, err := frontend.NewWitness(&scalarMulFixedBase{
+								S: s,
+								R: tEd.Point{X: expected.r[0], Y: expected.r[1]},
+							}, params.Field)
+							if err != nil {
+								t.Fatal(err)
+							}
+							err = ccs.IsSolved(w)
+							if expected.valid && err != nil {
+								t.Fatalf("%s, s=%s: %v", builder.name, s, err)
+							}
+							if !expected.valid && err == nil {
+								t.Fatalf("%s, s=%s: a wrong result was accepted", builder.name, s)
+							}
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestScalarMulFixedBaseInvalidBase(t *testing.T) {
+	id := twistededwards.BLS12_381_BANDERSNATCH
+	params := mustCurveParams(id)
+	// the points of curvePoints have a small order component
+	base := curvePoints(id, 1)[0]
+	if _, err := frontend.Compile(params.Field, r1cs.NewBuilder, &scalarMulFixedBase{curveID: id, base: base}); err == nil {
+		t.Fatal("a base outside the prime subgroup was accepted")
+	}
+}
+
+// BenchmarkScalarMulFixedBase prints the number of constraints of the
+// fixed-base scalar multiplications by the generator of Jubjub and
+// Bandersnatch.
+func BenchmarkScalarMulFixedBase(b *testing.B) {
+	for _, id := range []twistededwards.ID{twistededwards.BLS12_381, twistededwards.BLS12_381_BANDERSNATCH} {
+		for _, c := range []struct {
+			name string
+			log  bool
+		}{
+			{"fixed-base", false},
+			{"fixed-base with logup", true},
+		} {
+			circuit := scalarMulFixedBase{curveID: id, log: c.log, base: curveGenerator(id)}
+			p := profile.Start()
+			_, _ = frontend.Compile(ecc.BLS12_381.ScalarField(), scs.NewBuilder, &circuit)
+			p.Stop()
+			fmt.Printf("%s %s (scs): %d\n", curveNames[id], c.name, p.NbConstraints())
+
+			p = profile.Start()
+			_, _ = frontend.Compile(ecc.BLS12_381.ScalarField(), r1cs.NewBuilder, &circuit)
+			p.Stop()
+			fmt.Printf("%s %s (r1cs): %d\n", curveNames[id], c.name, p.NbConstraints())
+		}
+	}
+}
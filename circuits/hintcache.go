@@ -0,0 +1,121 @@
+package circuits
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/consensys/gnark/constraint/solver"
+)
+
+// HintCache memoises the outputs of the hints of this package, so that a
+// decomposition or a scalar multiplication repeated within one witness, e.g.
+// the same scalar multiplied by several points, is computed once.
+//
+// A HintCache is meant for one solver run: create one per witness and pass
+// its Options to the solver, e.g.
+//
+//	cache := circuits.NewHintCache()
+//	proof, err := groth16.Prove(ccs, pk, w, backend.WithSolverOptions(cache.Options()...))
+//
+// The cache keys contain the inputs of the hints, i.e. the scalars, and the
+// lookups compare them in variable time. This would undo UseConstantTimeHints,
+// so the cache is bypassed while constant-time hints are enabled: every call
+// then computes its outputs and counts as a miss.
+//
+// It is safe for concurrent use by the solver.
+type HintCache struct {
+	mu      sync.Mutex
+	entries map[string]*hintCacheEntry
+
+	hits, misses atomic.Int64
+}
+
+type hintCacheEntry struct {
+	once    sync.Once
+	outputs []*big.Int
+	err     error
+}
+
+// NewHintCache returns an empty HintCache.
+func NewHintCache() *HintCache {
+	return &HintCache{entries: make(map[string]*hintCacheEntry)}
+}
+
+// Options returns the solver options overriding the hints of this package by
+// their memoised counterparts.
+func (c *HintCache) Options() []solver.Option {
+	hints := GetHints()
+	opts := make([]solver.Option, len(hints))
+	for i, h := range hints {
+		opts[i] = solver.OverrideHint(solver.GetHintID(h), c.wrap(h))
+	}
+	return opts
+}
+
+// Stats returns the number of hint calls answered from the cache and the
+// number of calls that computed their outputs.
+func (c *HintCache) Stats() (hits, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+// wrap returns the memoised counterpart of h. Concurrent calls on the same
+// inputs wait for the first one to compute the outputs.
+func (c *HintCache) wrap(h solver.Hint) solver.Hint {
+	id := solver.GetHintID(h)
+	return func(field *big.Int, inputs, outputs []*big.Int) error {
+		if constantTimeHints.Load() {
+			c.misses.Add(1)
+			return h(field, inputs, outputs)
+		}
+		key := hintCacheKey(id, field, inputs, len(outputs))
+		c.mu.Lock()
+		e, ok := c.entries[key]
+		if !ok {
+			e = new(hintCacheEntry)
+			c.entries[key] = e
+		}
+		c.mu.Unlock()
+
+		computed := false
+		e.once.Do(func() {
+			computed = true
+			e.outputs = make([]*big.Int, len(outputs))
+			for i := range e.outputs {
+				e.outputs[i] = new(big.Int)
+			}
+			e.err = h(field, inputs, e.outputs)
+		})
+		if computed {
+			c.misses.Add(1)
+		} else {
+			c.hits.Add(1)
+		}
+		if e.err != nil {
+			return e.err
+		}
+		// the solver owns outputs, the cache keeps its own copy
+		for i := range outputs {
+			outputs[i].Set(e.outputs[i])
+		}
+		return nil
+	}
+}
+
+// hintCacheKey identifies a hint call by the hint, the field, the inputs and
+// the number of outputs.
+func hintCacheKey(id solver.HintID, field *big.Int, inputs []*big.Int, nbOutputs int) string {
+	var sb strings.Builder
+	sb.WriteString(strconv.FormatUint(uint64(id), 16))
+	sb.WriteByte(':')
+	sb.WriteString(strconv.Itoa(nbOutputs))
+	sb.WriteByte(':')
+	sb.WriteString(field.Text(16))
+	for _, x := range inputs {
+		sb.WriteByte(',')
+		sb.WriteString(x.Text(16))
+	}
+	return sb.String()
+}
@@ -0,0 +1,158 @@
+package circuits
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	tEd "github.com/consensys/gnark/std/algebra/native/twistededwards"
+)
+
+func TestHintCacheConcurrent(t *testing.T) {
+	var calls atomic.Int64
+	double := func(_ *big.Int, inputs, outputs []*big.Int) error {
+		calls.Add(1)
+		if inputs[0].Sign() < 0 {
+			return errors.New("negative input")
+		}
+		outputs[0].Lsh(inputs[0], 1)
+		return nil
+	}
+	cache := NewHintCache()
+	h := cache.wrap(double)
+	field := ecc.BLS12_381.ScalarField()
+
+	const nbCallers = 16
+	var wg sync.WaitGroup
+	for i := 0; i < nbCallers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			outputs := newBigInts(1)
+			if err := h(field, []*big.Int{big.NewInt(int64(i % 2))}, outputs); err != nil {
+				t.Error(err)
+				return
+			}
+			if outputs[0].Int64() != int64(2*(i%2)) {
+				t.Errorf("caller %d: wrong output %s", i, outputs[0])
+			}
+			// the cached outputs are copies
+			outputs[0].SetUint64(42)
+		}(i)
+	}
+	wg.Wait()
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 computations, got %d", calls.Load())
+	}
+	if hits, misses := cache.Stats(); hits != nbCallers-2 || misses != 2 {
+		t.Fatalf("expected %d hits and 2 misses, got %d and %d", nbCallers-2, hits, misses)
+	}
+
+	// errors are cached too
+	for i := 0; i < 2; i++ {
+		if err := h(field, []*big.Int{big.NewInt(-1)}, newBigInts(1)); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 computations, got %d", calls.Load())
+	}
+
+	// the number of outputs and the field are part of the key
+	if err := h(field, []*big.Int{big.NewInt(1)}, newBigInts(2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := h(ecc.BN254.ScalarField(), []*big.Int{big.NewInt(1)}, newBigInts(1)); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 5 {
+		t.Fatalf("expected 5 computations, got %d", calls.Load())
+	}
+}
+
+func TestHintCacheConstantTime(t *testing.T) {
+	UseConstantTimeHints(true)
+	defer UseConstantTimeHints(false)
+
+	var calls atomic.Int64
+	identity := func(_ *big.Int, inputs, outputs []*big.Int) error {
+		calls.Add(1)
+		outputs[0].Set(inputs[0])
+		return nil
+	}
+	cache := NewHintCache()
+	h := cache.wrap(identity)
+	field := ecc.BLS12_381.ScalarField()
+	for i := 0; i < 3; i++ {
+		outputs := newBigInts(1)
+		if err := h(field, []*big.Int{big.NewInt(7)}, outputs); err != nil {
+			t.Fatal(err)
+		}
+		if outputs[0].Int64() != 7 {
+			t.Fatalf("wrong output %s", outputs[0])
+		}
+	}
+	// no secret input is kept in the cache
+	if calls.Load() != 3 || len(cache.entries) != 0 {
+		t.Fatalf("expected 3 computations and no entry, got %d and %d", calls.Load(), len(cache.entries))
+	}
+	if hits, misses := cache.Stats(); hits != 0 || misses != 3 {
+		t.Fatalf("expected 0 hits and 3 misses, got %d and %d", hits, misses)
+	}
+}
+
+type sharedScalarMul struct {
+	P1, P2 tEd.Point
+	R1, R2 tEd.Point
+	S      frontend.Variable
+}
+
+func (circuit *sharedScalarMul) Define(api frontend.API) error {
+	for _, pr := range [][2]*tEd.Point{{&circuit.P1, &circuit.R1}, {&circuit.P2, &circuit.R2}} {
+		res := ScalarMulGLVAndFakeGLVLog(api, pr[0], circuit.S)
+		api.AssertIsEqual(res.X, pr[1].X)
+		api.AssertIsEqual(res.Y, pr[1].Y)
+	}
+	return nil
+}
+
+func TestHintCacheSharedScalar(t *testing.T) {
+	id := twistededwards.BLS12_381_BANDERSNATCH
+	field := ecc.BLS12_381.ScalarField()
+	p1, r1, s := scalarMulAssignments(t, id)
+	// P2 = [s]P1
+	p2 := r1
+	r2 := [2]*big.Int{new(big.Int), new(big.Int)}
+	if err := scalarMulNative(id, p2[0], p2[1], s, r2[0], r2[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	ccs, err := frontend.Compile(field, scs.NewBuilder, &sharedScalarMul{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := frontend.NewWitness(&sharedScalarMul{
+		P1: tEd.Point{X: p1[0], Y: p1[1]},
+		P2: tEd.Point{X: p2[0], Y: p2[1]},
+		R1: tEd.Point{X: r1[0], Y: r1[1]},
+		R2: tEd.Point{X: r2[0], Y: r2[1]},
+		S:  s,
+	}, field)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewHintCache()
+	if err := ccs.IsSolved(w, cache.Options()...); err != nil {
+		t.Fatal(err)
+	}
+	// the decomposition of s is shared by the two multiplications
+	if hits, _ := cache.Stats(); hits == 0 {
+		t.Fatal("the decomposition was computed twice")
+	}
+}
@@ -0,0 +1,236 @@
+// Package hintdebug runs the solver hints of the circuits package outside of
+// the solver and checks their outputs against native references.
+//
+// A bad hint output only shows up as an unsatisfied constraint deep in the
+// solver. Running the hint by name on the same inputs and checking it against
+// its reference points at the faulty output directly:
+//
+//	report, err := hintdebug.Check("halfGCDZZ2Combined", ecc.BLS12_381.ScalarField(), inputs)
+//	if err != nil {
+//		return err
+//	}
+//	if !report.OK() {
+//		fmt.Println(report)
+//	}
+//
+// The references do not reuse the algorithms of the hints. The outputs of the
+// scalar multiplication and of the limb decomposition are unique: they are
+// recomputed, with a big.Int double-and-add and from the binary expansion of
+// the scalar, and compared one by one. The decompositions have many valid
+// outputs, e.g. the opposite of a valid one, so their references check the
+// relation that the circuits verify, such as s1 + s*s2 = 0 mod r, the
+// non-degeneracy of the decomposition and the documented bounds of the
+// outputs, and report the properties that do not hold.
+package hintdebug
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/consensys/gnark/constraint/solver"
+	_ "github.com/yelhousni/jubjub-vs-bandersnatch/circuits" // registers the hints
+)
+
+// circuitsPath prefixes the names of the hints of the circuits package.
+const circuitsPath = "github.com/yelhousni/jubjub-vs-bandersnatch/circuits."
+
+// Lookup returns the registered hint with the given name. The name is either
+// the full name returned by solver.GetHintName, e.g.
+// "github.com/yelhousni/jubjub-vs-bandersnatch/circuits.halfGCD", or its
+// unqualified suffix, e.g. "halfGCD". An unqualified name designates the hint
+// of the circuits package if there is one, and must not be ambiguous
+// otherwise, as gnark registers hints of its own.
+func Lookup(name string) (solver.Hint, error) {
+	var found []solver.Hint
+	for _, h := range solver.GetRegisteredHints() {
+		full := solver.GetHintName(h)
+		if full == name || full == circuitsPath+name {
+			return h, nil
+		}
+		if shortName(full) == name {
+			found = append(found, h)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("hintdebug: no registered hint named %q", name)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("hintdebug: hint name %q is ambiguous, use the full name", name)
+	}
+}
+
+// shortName strips the package path of a full hint name.
+func shortName(full string) string {
+	return full[strings.LastIndexByte(full, '.')+1:]
+}
+
+// Names returns the sorted names of the hints that have a reference
+// implementation.
+func Names() []string {
+	names := make([]string, 0, len(references))
+	for name := range references {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NbOutputs returns the number of outputs of the hint name on the inputs, as
+// used by the circuits.
+func NbOutputs(name string, inputs []*big.Int) (int, error) {
+	ref, ok := references[shortName(name)]
+	if !ok {
+		return 0, fmt.Errorf("hintdebug: no reference implementation for %q", name)
+	}
+	return ref.nbOutputs(inputs), nil
+}
+
+// Run invokes the registered hint name on the SNARK field and the inputs, and
+// returns its nbOutputs outputs.
+func Run(name string, field *big.Int, inputs []*big.Int, nbOutputs int) ([]*big.Int, error) {
+	h, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return run(h, field, inputs, nbOutputs)
+}
+
+// run calls h on copies of the inputs, so that the hint cannot modify them,
+// and reports a panic of the hint as an error.
+func run(h solver.Hint, field *big.Int, inputs []*big.Int, nbOutputs int) (outputs []*big.Int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("hint panicked: %v", r)
+		}
+	}()
+	in := make([]*big.Int, len(inputs))
+	for i := range inputs {
+		in[i] = new(big.Int).Set(inputs[i])
+	}
+	outputs = make([]*big.Int, nbOutputs)
+	for i := range outputs {
+		outputs[i] = new(big.Int)
+	}
+	return outputs, h(field, in, outputs)
+}
+
+// Check runs the registered hint name on the SNARK field and the inputs and
+// checks its outputs against the reference.
+func Check(name string, field *big.Int, inputs []*big.Int) (*Report, error) {
+	h, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(name, h, field, inputs)
+}
+
+// Compare is like Check but runs h in place of the registered hint name, e.g.
+// a replacement passed to solver.OverrideHint.
+//
+// The returned error is about the comparison itself, i.e. an unknown name or
+// a reference failing on the inputs. An error of the hint is recorded in the
+// report.
+func Compare(name string, h solver.Hint, field *big.Int, inputs []*big.Int) (*Report, error) {
+	ref, ok := references[shortName(name)]
+	if !ok {
+		return nil, fmt.Errorf("hintdebug: no reference implementation for %q", name)
+	}
+	report := &Report{
+		Name:   shortName(name),
+		Inputs: inputs,
+	}
+	nbOutputs := ref.nbOutputs(inputs)
+	if ref.compute != nil {
+		expected, err := ref.compute(field, inputs)
+		if err != nil {
+			return nil, fmt.Errorf("hintdebug: reference %s: %w", report.Name, err)
+		}
+		report.Expected = expected
+		nbOutputs = len(expected)
+	}
+	report.Outputs, report.Err = run(h, field, inputs, nbOutputs)
+	if report.Err != nil {
+		return report, nil
+	}
+	for i := range report.Expected {
+		if report.Outputs[i].Cmp(report.Expected[i]) != 0 {
+			report.Mismatches = append(report.Mismatches, i)
+		}
+	}
+	if ref.verify != nil {
+		violations, err := ref.verify(field, inputs, report.Outputs)
+		if err != nil {
+			return nil, fmt.Errorf("hintdebug: reference %s: %w", report.Name, err)
+		}
+		report.Violations = violations
+	}
+	return report, nil
+}
+
+// Report is the result of Check or Compare.
+type Report struct {
+	Name     string
+	Inputs   []*big.Int
+	Outputs  []*big.Int // outputs of the hint
+	Expected []*big.Int // outputs of the reference, if it computes them
+	// Err is the error returned by the hint, if any.
+	Err error
+	// Mismatches are the indices of the outputs that differ from the
+	// reference.
+	Mismatches []int
+	// Violations describe the properties of the outputs that do not hold,
+	// for the references that check the outputs rather than compute them.
+	Violations []string
+}
+
+// OK reports whether the hint succeeded and matches the reference.
+func (r *Report) OK() bool {
+	return r.Err == nil && len(r.Mismatches) == 0 && len(r.Violations) == 0
+}
+
+// String pretty-prints the report, one input or output per line. The
+// mismatched outputs are marked with ✗ and followed by the expected value,
+// and the violated properties are listed after the outputs.
+func (r *Report) String() string {
+	var sb strings.Builder
+	switch {
+	case r.Err != nil:
+		fmt.Fprintf(&sb, "%s: error: %v\n", r.Name, r.Err)
+	case len(r.Mismatches) != 0:
+		fmt.Fprintf(&sb, "%s: %d of %d outputs differ from the reference\n", r.Name, len(r.Mismatches), len(r.Expected))
+	case len(r.Violations) != 0:
+		fmt.Fprintf(&sb, "%s: %d properties of the outputs do not hold\n", r.Name, len(r.Violations))
+	default:
+		fmt.Fprintf(&sb, "%s: ok\n", r.Name)
+	}
+	for i, x := range r.Inputs {
+		fmt.Fprintf(&sb, "  input  %2d   %s\n", i, x)
+	}
+	if r.Err != nil {
+		return sb.String()
+	}
+	mismatch := make(map[int]bool, len(r.Mismatches))
+	for _, i := range r.Mismatches {
+		mismatch[i] = true
+	}
+	for i, x := range r.Outputs {
+		if mismatch[i] {
+			fmt.Fprintf(&sb, "  output %2d ✗ %s\n", i, x)
+			fmt.Fprintf(&sb, "   expected   %s\n", r.Expected[i])
+		} else {
+			fmt.Fprintf(&sb, "  output %2d   %s\n", i, x)
+		}
+	}
+	for _, v := range r.Violations {
+		fmt.Fprintf(&sb, "  ✗ %s\n", v)
+	}
+	return sb.String()
+}
+
+// errNbInputs is returned by the references for a wrong number of inputs.
+var errNbInputs = errors.New("wrong number of inputs")
@@ -0,0 +1,245 @@
+package hintdebug
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	tEd "github.com/consensys/gnark/std/algebra/native/twistededwards"
+)
+
+// the eigenvalue of the Bandersnatch endomorphism
+var lambda, _ = new(big.Int).SetString("8913659658109529928382530854484400854125314752504019737736543920008458395397", 10)
+
+// the order of the prime subgroup of Bandersnatch
+var order, _ = new(big.Int).SetString("13108968793781547619861935127046491459309155893440570251786403306729687672801", 10)
+
+var curveIDs = []twistededwards.ID{
+	twistededwards.BN254,
+	twistededwards.BLS12_377,
+	twistededwards.BLS12_381,
+	twistededwards.BLS12_381_BANDERSNATCH,
+	twistededwards.BW6_761,
+	twistededwards.BW6_633,
+	twistededwards.BLS24_315,
+	twistededwards.BLS24_317,
+}
+
+// scalars returns 0, 1, r-1, r and a few random scalars below r.
+func scalars(r *big.Int) []*big.Int {
+	res := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		new(big.Int).Sub(r, big.NewInt(1)),
+		new(big.Int).Set(r),
+	}
+	for i := 0; i < 4; i++ {
+		s, _ := rand.Int(rand.Reader, r)
+		res = append(res, s)
+	}
+	return res
+}
+
+func check(t *testing.T, name string, field *big.Int, inputs []*big.Int) {
+	t.Helper()
+	report, err := Check(name, field, inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() {
+		t.Fatalf("\n%s", report)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for _, id := range curveIDs {
+		params, err := tEd.GetCurveParams(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		field, _ := tEd.GetSnarkField(id)
+		ss := scalars(params.Order)
+		for i, s := range ss {
+			check(t, "halfGCD", field, []*big.Int{s, params.Order})
+			check(t, "jointDecompose", field, []*big.Int{s, ss[len(ss)-1-i], params.Order})
+			check(t, "scalarMulHint", field, []*big.Int{params.Base[0], params.Base[1], s, big.NewInt(int64(id))})
+		}
+		check(t, "msmDecompose", field, append(ss[:len(ss):len(ss)], params.Order))
+	}
+
+	// every scalar for small orders
+	for _, r := range []int64{2, 3, 101, 1009} {
+		for s := int64(0); s < r; s++ {
+			check(t, "halfGCD", nil, []*big.Int{big.NewInt(s), big.NewInt(r)})
+		}
+	}
+
+	field := ecc.BLS12_381.ScalarField()
+	batch := []*big.Int{lambda}
+	for _, s := range scalars(order) {
+		check(t, "halfGCDZZ2Combined", field, []*big.Int{s, lambda})
+		check(t, "glvDecompose", field, []*big.Int{s, lambda})
+		check(t, "decompose", field, []*big.Int{big.NewInt(4), big.NewInt(64), s})
+		check(t, "decompose", field, []*big.Int{big.NewInt(5), big.NewInt(51), s})
+		batch = append(batch, s)
+	}
+	for i, s := range batch[2:] {
+		check(t, "jointDecomposeGLV", field, []*big.Int{batch[1+i], s, lambda})
+	}
+	check(t, "halfGCDZZ2Batch", field, batch)
+	check(t, "msmDecomposeGLV", field, append(batch[1:5:5], lambda))
+}
+
+func TestCompare(t *testing.T) {
+	field := ecc.BLS12_381.ScalarField()
+	s, _ := rand.Int(rand.Reader, field)
+	inputs := []*big.Int{s, lambda}
+
+	h, err := Lookup("halfGCDZZ2Combined")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// flip the sign of v1
+	tampered := func(mod *big.Int, inputs, outputs []*big.Int) error {
+		if err := h(mod, inputs, outputs); err != nil {
+			return err
+		}
+		outputs[6].Xor(outputs[6], big.NewInt(1))
+		return nil
+	}
+	report, err := Compare("halfGCDZZ2Combined", tampered, field, inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK() || len(report.Violations) != 1 || !strings.Contains(report.Violations[0], "mod r") {
+		t.Fatalf("expected the relation to fail, got %v", report.Violations)
+	}
+	if !strings.Contains(report.String(), "✗") {
+		t.Fatalf("the report does not show the violation:\n%s", report)
+	}
+
+	// u1 + r verifies the relation but not the bound
+	shifted := func(mod *big.Int, inputs, outputs []*big.Int) error {
+		if err := h(mod, inputs, outputs); err != nil {
+			return err
+		}
+		if outputs[4].Sign() == 0 {
+			outputs[0].Add(outputs[0], order)
+		} else {
+			outputs[0].Sub(order, outputs[0])
+			outputs[4].SetUint64(0)
+		}
+		return nil
+	}
+	report, err = Compare("halfGCDZZ2Combined", shifted, field, inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK() || len(report.Violations) != 1 || !strings.Contains(report.Violations[0], "|u1|") {
+		t.Fatalf("expected the bound of u1 to fail, got %v", report.Violations)
+	}
+
+	// the opposite decomposition is valid too
+	hGCD, err := Lookup("halfGCD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opposite := func(mod *big.Int, inputs, outputs []*big.Int) error {
+		if err := hGCD(mod, inputs, outputs); err != nil {
+			return err
+		}
+		for i := 2; i < 4; i++ {
+			outputs[i].Xor(outputs[i], big.NewInt(1))
+		}
+		return nil
+	}
+	report, err = Compare("halfGCD", opposite, field, []*big.Int{s, order})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() {
+		t.Fatalf("\n%s", report)
+	}
+
+	// the hint rejects the other root of j² + 2
+	otherLambda := new(big.Int).Sub(order, lambda)
+	report, err = Check("halfGCDZZ2Combined", field, []*big.Int{s, otherLambda})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Err == nil || report.OK() {
+		t.Fatal("expected the hint to fail")
+	}
+}
+
+func TestCompareJoint(t *testing.T) {
+	field := ecc.BLS12_381.ScalarField()
+	a, _ := rand.Int(rand.Reader, order)
+	b, _ := rand.Int(rand.Reader, order)
+	for _, tc := range []struct {
+		name   string
+		inputs []*big.Int
+		sign   int // index of the sign bit of the first component of v
+	}{
+		{"jointDecompose", []*big.Int{a, b, order}, 5},
+		{"jointDecomposeGLV", []*big.Int{a, b, lambda}, 10},
+		{"msmDecompose", []*big.Int{a, b, order}, 5},
+		{"msmDecomposeGLV", []*big.Int{a, b, lambda}, 10},
+	} {
+		h, err := Lookup(tc.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// v ↦ -v breaks both relations
+		tampered := func(mod *big.Int, inputs, outputs []*big.Int) error {
+			if err := h(mod, inputs, outputs); err != nil {
+				return err
+			}
+			for i := tc.sign; i < len(outputs); i++ {
+				outputs[i].Xor(outputs[i], big.NewInt(1))
+			}
+			return nil
+		}
+		report, err := Compare(tc.name, tampered, field, tc.inputs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.OK() || len(report.Violations) != 2 {
+			t.Fatalf("%s: expected both relations to fail, got %v", tc.name, report.Violations)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	const full = "github.com/yelhousni/jubjub-vs-bandersnatch/circuits.halfGCD"
+	h, err := Lookup(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2, err := Lookup("halfGCD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := []*big.Int{big.NewInt(42), big.NewInt(101)}
+	out, _ := run(h, nil, inputs, 4)
+	out2, _ := run(h2, nil, inputs, 4)
+	for i := range out {
+		if out[i].Cmp(out2[i]) != 0 {
+			t.Fatal("the full and the short names resolve to different hints")
+		}
+	}
+	if _, err := Lookup("noSuchHint"); err == nil {
+		t.Fatal("expected an error for an unknown hint")
+	}
+	if _, err := Run("halfGCD", nil, inputs[:1], 4); err == nil {
+		t.Fatal("expected an error for a wrong number of inputs")
+	}
+	for _, name := range Names() {
+		if _, err := Lookup(name); err != nil {
+			t.Fatalf("reference %s has no registered hint: %v", name, err)
+		}
+	}
+}
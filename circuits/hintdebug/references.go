@@ -0,0 +1,424 @@
+package hintdebug
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/bandersnatch"
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	tEd "github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/yelhousni/jubjub-vs-bandersnatch/zz2"
+)
+
+// reference is a native reference of a hint. The outputs of a scalar
+// multiplication or of a limb decomposition are unique, and compute
+// recomputes them. The decompositions have many valid outputs, so verify
+// checks the relation the circuits rely on and the bounds of the outputs
+// rather than recomputing them with the algorithm of the hint.
+type reference struct {
+	// nbOutputs returns the number of outputs for the inputs.
+	nbOutputs func(inputs []*big.Int) int
+	// compute returns the expected outputs, or is nil.
+	compute func(field *big.Int, inputs []*big.Int) ([]*big.Int, error)
+	// verify returns the properties that the outputs violate, or is nil.
+	verify func(field *big.Int, inputs, outputs []*big.Int) ([]string, error)
+}
+
+// references maps the unqualified hint names to their references.
+var references = map[string]reference{
+	"halfGCD": {
+		nbOutputs: func([]*big.Int) int { return 4 },
+		verify:    verifyHalfGCD,
+	},
+	"glvDecompose": {
+		nbOutputs: func([]*big.Int) int { return 4 },
+		verify:    verifyGLVDecompose,
+	},
+	"scalarMulHint": {
+		nbOutputs: func([]*big.Int) int { return 2 },
+		compute:   scalarMulRef,
+	},
+	"halfGCDZZ2Combined": {
+		nbOutputs: func([]*big.Int) int { return 8 },
+		verify:    verifyHalfGCDZZ2Combined,
+	},
+	"halfGCDZZ2Batch": {
+		nbOutputs: func(inputs []*big.Int) int { return 8 * (len(inputs) - 1) },
+		verify:    verifyHalfGCDZZ2Batch,
+	},
+	"jointDecompose": {
+		nbOutputs: func([]*big.Int) int { return 6 },
+		verify:    verifyMultiDecompose,
+	},
+	"jointDecomposeGLV": {
+		nbOutputs: func([]*big.Int) int { return 12 },
+		verify:    verifyMultiDecomposeGLV,
+	},
+	"msmDecompose": {
+		nbOutputs: func(inputs []*big.Int) int { return 2 * len(inputs) },
+		verify:    verifyMultiDecompose,
+	},
+	"msmDecomposeGLV": {
+		nbOutputs: func(inputs []*big.Int) int { return 4 * len(inputs) },
+		verify:    verifyMultiDecomposeGLV,
+	},
+	"decompose": {
+		nbOutputs: func(inputs []*big.Int) int {
+			if len(inputs) != 3 || !inputs[0].IsUint64() {
+				return 0
+			}
+			return int(inputs[0].Uint64())
+		},
+		compute: decomposeRef,
+	},
+}
+
+// unsigned returns the n values laid out by the decomposition hints as their
+// magnitudes followed by their sign bits (1 if negative). It appends to
+// violations the sign bits that are not bits.
+func unsigned(outputs []*big.Int, n int, violations *[]string) []*big.Int {
+	res := make([]*big.Int, n)
+	for i := range res {
+		res[i] = new(big.Int).Set(outputs[i])
+		switch {
+		case outputs[n+i].Cmp(big.NewInt(1)) == 0:
+			res[i].Neg(res[i])
+		case outputs[n+i].Sign() != 0:
+			*violations = append(*violations, fmt.Sprintf("output %d is not a sign bit", n+i))
+		}
+	}
+	return res
+}
+
+// isZeroMod reports whether x = 0 mod r.
+func isZeroMod(x, r *big.Int) bool {
+	return new(big.Int).Mod(x, r).Sign() == 0
+}
+
+// checkBound appends to violations the values whose square is not below
+// bound2. bound describes the bound in the messages, e.g. "< 2·√r".
+func checkBound(violations *[]string, names []string, values []*big.Int, bound2 *big.Int, bound string) {
+	for i, v := range values {
+		if new(big.Int).Mul(v, v).Cmp(bound2) >= 0 {
+			*violations = append(*violations, fmt.Sprintf("|%s| = %s is out of bounds (%s)", names[i], new(big.Int).Abs(v), bound))
+		}
+	}
+}
+
+// verifyHalfGCD checks, given (s, r), that the outputs (s1, s2) verify
+// s1 + s*s2 = 0 mod r with s2 ≠ 0 and |s1|, |s2| < 2·√r.
+func verifyHalfGCD(_ *big.Int, inputs, outputs []*big.Int) ([]string, error) {
+	if len(inputs) != 2 {
+		return nil, errNbInputs
+	}
+	r := inputs[1]
+	if r.Sign() <= 0 {
+		return nil, errors.New("the order must be positive")
+	}
+	var violations []string
+	k := unsigned(outputs, 2, &violations)
+	if k[1].Sign() == 0 {
+		violations = append(violations, "s2 = 0")
+	}
+	var rel big.Int
+	rel.Mul(inputs[0], k[1]).Add(&rel, k[0])
+	if !isZeroMod(&rel, r) {
+		violations = append(violations, "s1 + s*s2 != 0 mod r")
+	}
+	// s² < 4r
+	checkBound(&violations, []string{"s1", "s2"}, k, new(big.Int).Lsh(r, 2), "< 2·√r")
+	return violations, nil
+}
+
+// bandersnatchOrder returns the order r of Bandersnatch after checking that
+// the SNARK field is its base field and that λ is a root of j² + 2 mod r.
+func bandersnatchOrder(field, lambda *big.Int) (*big.Int, error) {
+	if field.Cmp(ecc.BLS12_381.ScalarField()) != 0 {
+		return nil, errors.New("Bandersnatch is not defined over the SNARK field")
+	}
+	curve := bandersnatch.GetEdwardsCurve()
+	r := &curve.Order
+	var l2 big.Int
+	l2.Mul(lambda, lambda).Add(&l2, big.NewInt(2))
+	if !isZeroMod(&l2, r) {
+		return nil, errors.New("λ is not a square root of -2 mod r")
+	}
+	return r, nil
+}
+
+// verifyGLVDecompose checks, given (s, λ), that the outputs (s1, s2) verify
+// s1 + λ*s2 = s mod r on Bandersnatch with s1² + 2*s2² < r, i.e. that
+// s1 + s2*j is a short representative of s modulo π in Z[√−2].
+func verifyGLVDecompose(field *big.Int, inputs, outputs []*big.Int) ([]string, error) {
+	if len(inputs) != 2 {
+		return nil, errNbInputs
+	}
+	r, err := bandersnatchOrder(field, inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	var violations []string
+	k := unsigned(outputs, 2, &violations)
+	var rel big.Int
+	rel.Mul(inputs[1], k[1]).Add(&rel, k[0]).Sub(&rel, inputs[0])
+	if !isZeroMod(&rel, r) {
+		violations = append(violations, "s1 + λ*s2 != s mod r")
+	}
+	var norm big.Int
+	norm.Mul(k[1], k[1]).Lsh(&norm, 1).Add(&norm, new(big.Int).Mul(k[0], k[0]))
+	if norm.Cmp(r) >= 0 {
+		violations = append(violations, fmt.Sprintf("s1² + 2*s2² = %s is not below r", &norm))
+	}
+	return violations, nil
+}
+
+// scalarMulRef returns [s]P given (x, y, s, id), with a double-and-add in
+// projective coordinates over big.Int.
+func scalarMulRef(field *big.Int, inputs []*big.Int) ([]*big.Int, error) {
+	if len(inputs) != 4 {
+		return nil, errNbInputs
+	}
+	if !inputs[3].IsUint64() || inputs[3].Uint64() > 0xff {
+		return nil, errors.New("unknown twisted edwards curve id")
+	}
+	id := twistededwards.ID(inputs[3].Uint64())
+	params, err := tEd.GetCurveParams(id)
+	if err != nil {
+		return nil, err
+	}
+	if snarkField, err := tEd.GetSnarkField(id); err != nil || snarkField.Cmp(field) != 0 {
+		return nil, errors.New("the curve is not defined over the SNARK field")
+	}
+
+	// a*x² + y² == 1 + d*x²*y²
+	x := new(big.Int).Mod(inputs[0], field)
+	y := new(big.Int).Mod(inputs[1], field)
+	var x2, y2, lhs, rhs big.Int
+	x2.Mul(x, x)
+	y2.Mul(y, y)
+	lhs.Mul(params.A, &x2).Add(&lhs, &y2).Mod(&lhs, field)
+	rhs.Mul(params.D, &x2).Mul(&rhs, &y2).Add(&rhs, big.NewInt(1)).Mod(&rhs, field)
+	if lhs.Cmp(&rhs) != 0 {
+		return nil, errors.New("P is not on the curve")
+	}
+
+	p := projective{x, y, big.NewInt(1)}
+	res := projective{big.NewInt(0), big.NewInt(1), big.NewInt(1)}
+	for i := inputs[2].BitLen() - 1; i >= 0; i-- {
+		res = res.add(res, params.A, params.D, field)
+		if inputs[2].Bit(i) == 1 {
+			res = res.add(p, params.A, params.D, field)
+		}
+	}
+	if res[2].Sign() == 0 {
+		return nil, errors.New("[s]P is not an affine point")
+	}
+	var zInv big.Int
+	zInv.ModInverse(res[2], field)
+	res[0].Mul(res[0], &zInv).Mod(res[0], field)
+	res[1].Mul(res[1], &zInv).Mod(res[1], field)
+	return []*big.Int{res[0], res[1]}, nil
+}
+
+// projective is a point (X : Y : Z) on ax² + y² = 1 + dx²y².
+type projective [3]*big.Int
+
+// add returns p + q with the unified addition of Bernstein et al.
+// (add-2008-bbjlp), which also doubles.
+func (p projective) add(q projective, a, d, field *big.Int) projective {
+	var A, B, C, D, E, F, G, t0, t1 big.Int
+	A.Mul(p[2], q[2])
+	B.Mul(&A, &A)
+	C.Mul(p[0], q[0])
+	D.Mul(p[1], q[1])
+	E.Mul(d, &C).Mul(&E, &D)
+	F.Sub(&B, &E)
+	G.Add(&B, &E)
+	t0.Add(p[0], p[1])
+	t1.Add(q[0], q[1])
+	t0.Mul(&t0, &t1).Sub(&t0, &C).Sub(&t0, &D)
+	t1.Mul(a, &C)
+	t1.Sub(&D, &t1)
+
+	var res projective
+	res[0] = new(big.Int).Mul(&A, &F)
+	res[0].Mul(res[0], &t0).Mod(res[0], field)
+	res[1] = new(big.Int).Mul(&A, &G)
+	res[1].Mul(res[1], &t1).Mod(res[1], field)
+	res[2] = new(big.Int).Mul(&F, &G)
+	res[2].Mod(res[2], field)
+	return res
+}
+
+// verifyHalfGCDZZ2Combined checks, given (s, λ), that the outputs (u1, u2,
+// v1, v2) verify
+//
+//	u1 + λ*u2 + s*(v1 + λ*v2) = 0 mod r
+//
+// on Bandersnatch with (v1, v2) ≠ (0, 0) and all the values at most
+// zz2.HalfGCDBound(r) ≈ 4·∜r in absolute value.
+func verifyHalfGCDZZ2Combined(field *big.Int, inputs, outputs []*big.Int) ([]string, error) {
+	if len(inputs) != 2 {
+		return nil, errNbInputs
+	}
+	r, err := bandersnatchOrder(field, inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	return verifyZZ2(inputs[0], inputs[1], r, outputs), nil
+}
+
+// verifyHalfGCDZZ2Batch is verifyHalfGCDZZ2Combined on each scalar of
+// (λ, s...).
+func verifyHalfGCDZZ2Batch(field *big.Int, inputs, outputs []*big.Int) ([]string, error) {
+	if len(inputs) < 2 {
+		return nil, errNbInputs
+	}
+	r, err := bandersnatchOrder(field, inputs[0])
+	if err != nil {
+		return nil, err
+	}
+	var violations []string
+	for i, s := range inputs[1:] {
+		for _, v := range verifyZZ2(s, inputs[0], r, outputs[8*i:8*(i+1)]) {
+			violations = append(violations, fmt.Sprintf("scalar %d: %s", i, v))
+		}
+	}
+	return violations, nil
+}
+
+// verifyZZ2 checks the eight outputs of the Z[√−2] decomposition of s.
+func verifyZZ2(s, lambda, r *big.Int, outputs []*big.Int) []string {
+	var violations []string
+	k := unsigned(outputs, 4, &violations)
+	if k[2].Sign() == 0 && k[3].Sign() == 0 {
+		violations = append(violations, "v = 0")
+	}
+	var u, v big.Int
+	u.Mul(lambda, k[1]).Add(&u, k[0])
+	v.Mul(lambda, k[3]).Add(&v, k[2]).Mul(&v, s).Add(&v, &u)
+	if !isZeroMod(&v, r) {
+		violations = append(violations, "u1 + λ*u2 + s*(v1 + λ*v2) != 0 mod r")
+	}
+	// |x| ≤ B, i.e. x² < B² + 1
+	bound := zz2.HalfGCDBound(r)
+	bound2 := new(big.Int).Mul(bound, bound)
+	bound2.Add(bound2, big.NewInt(1))
+	checkBound(&violations, []string{"u1", "u2", "v1", "v2"}, k, bound2, "≤ "+bound.String())
+	return violations
+}
+
+// checkLLLBound appends to violations a violation if the vector k is not
+// within the bound of the first vector of an LLL-reduced basis (δ = 99/100)
+// of a d-dimensional lattice of determinant r^e:
+//
+//	|k| ≤ (4/(4δ-1))^((d-1)/4) * r^(e/d), i.e. |k|^(2d) ≤ (100/74)^(d(d-1)/2) * r^(2e).
+func checkLLLBound(violations *[]string, k []*big.Int, r *big.Int, e int) {
+	d := len(k)
+	var norm2, lhs, rhs, t big.Int
+	for _, x := range k {
+		norm2.Add(&norm2, t.Mul(x, x))
+	}
+	m := big.NewInt(int64(d * (d - 1) / 2))
+	lhs.Exp(&norm2, big.NewInt(int64(d)), nil).Mul(&lhs, t.Exp(big.NewInt(74), m, nil))
+	rhs.Exp(r, big.NewInt(int64(2*e)), nil).Mul(&rhs, t.Exp(big.NewInt(100), m, nil))
+	if lhs.Cmp(&rhs) > 0 {
+		*violations = append(*violations, fmt.Sprintf("the outputs are longer than an LLL-reduced vector (|k|² = %s)", &norm2))
+	}
+}
+
+// verifyMultiDecompose checks, given (s_1, ..., s_N, r), that the outputs
+// (u_1, ..., u_N, v) verify
+//
+//	u_i + s_i*v = 0 mod r for all i
+//
+// with v ≠ 0 and (u_1, ..., u_N, v) within the LLL bound, about
+// r^(N/(N+1)). It checks jointDecompose, for N = 2, and msmDecompose.
+func verifyMultiDecompose(_ *big.Int, inputs, outputs []*big.Int) ([]string, error) {
+	if len(inputs) < 2 {
+		return nil, errNbInputs
+	}
+	n := len(inputs) - 1
+	r := inputs[n]
+	if r.Sign() <= 0 {
+		return nil, errors.New("the order must be positive")
+	}
+	var violations []string
+	k := unsigned(outputs, n+1, &violations)
+	if k[n].Sign() == 0 {
+		violations = append(violations, "v = 0")
+	}
+	for i := 0; i < n; i++ {
+		var rel big.Int
+		rel.Mul(inputs[i], k[n]).Add(&rel, k[i])
+		if !isZeroMod(&rel, r) {
+			violations = append(violations, fmt.Sprintf("u%d + s%d*v != 0 mod r", i+1, i+1))
+		}
+	}
+	checkLLLBound(&violations, k, r, n)
+	return violations, nil
+}
+
+// verifyMultiDecomposeGLV checks, given (s_1, ..., s_N, λ), that the outputs
+// (u_1,1, u_1,2, ..., u_N,1, u_N,2, v1, v2) verify
+//
+//	u_i,1 + λ*u_i,2 + s_i*(v1 + λ*v2) = 0 mod r for all i
+//
+// on Bandersnatch with (v1, v2) ≠ (0, 0) and the outputs within the LLL
+// bound, about r^(N/(2N+2)). It checks jointDecomposeGLV, for N = 2, and
+// msmDecomposeGLV.
+func verifyMultiDecomposeGLV(field *big.Int, inputs, outputs []*big.Int) ([]string, error) {
+	if len(inputs) < 2 {
+		return nil, errNbInputs
+	}
+	n := len(inputs) - 1
+	lambda := inputs[n]
+	r, err := bandersnatchOrder(field, lambda)
+	if err != nil {
+		return nil, err
+	}
+	var violations []string
+	k := unsigned(outputs, 2*n+2, &violations)
+	if k[2*n].Sign() == 0 && k[2*n+1].Sign() == 0 {
+		violations = append(violations, "v = 0")
+	}
+	var v big.Int
+	v.Mul(lambda, k[2*n+1]).Add(&v, k[2*n])
+	for i := 0; i < n; i++ {
+		var rel big.Int
+		rel.Mul(lambda, k[2*i+1]).Add(&rel, k[2*i])
+		rel.Add(&rel, new(big.Int).Mul(inputs[i], &v))
+		if !isZeroMod(&rel, r) {
+			violations = append(violations, fmt.Sprintf("u%d,1 + λ*u%d,2 + s%d*v != 0 mod r", i+1, i+1, i+1))
+		}
+	}
+	checkLLLBound(&violations, k, r, n)
+	return violations, nil
+}
+
+// decomposeRef returns the n w-bit limbs of s given (n, w, s), from the
+// binary expansion of s.
+func decomposeRef(_ *big.Int, inputs []*big.Int) ([]*big.Int, error) {
+	if len(inputs) != 3 {
+		return nil, errNbInputs
+	}
+	if !inputs[0].IsUint64() || !inputs[1].IsUint64() || inputs[1].Uint64() == 0 || inputs[1].Uint64() > 64 {
+		return nil, errors.New("invalid limb parameters")
+	}
+	n, w := int(inputs[0].Uint64()), int(inputs[1].Uint64())
+	s := inputs[2]
+	if s.Sign() < 0 || s.BitLen() > n*w {
+		return nil, errors.New("the scalar does not fit in the limbs")
+	}
+	res := make([]*big.Int, n)
+	for i := range res {
+		res[i] = new(big.Int)
+		for j := w - 1; j >= 0; j-- {
+			res[i].Lsh(res[i], 1)
+			res[i].SetBit(res[i], 0, s.Bit(i*w+j))
+		}
+	}
+	return res, nil
+}
@@ -2,12 +2,12 @@ package circuits
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
-	"sync"
+	"sync/atomic"
 
 	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark-crypto/ecc/bls12-381/bandersnatch"
-	jubjub "github.com/consensys/gnark-crypto/ecc/bls12-381/twistededwards"
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
 	"github.com/consensys/gnark/constraint/solver"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/math/emulated"
@@ -17,10 +17,14 @@ import (
 func GetHints() []solver.Hint {
 	return []solver.Hint{
 		halfGCD,
+		glvDecompose,
+		jointDecompose,
+		jointDecomposeGLV,
+		msmDecompose,
+		msmDecomposeGLV,
 		scalarMulHint,
-		halfGCDZZ2,
-		halfGCDZZ2Emulated,
-		halfGCDZZ2Signs,
+		halfGCDZZ2Combined,
+		halfGCDZZ2Batch,
 		decompose,
 	}
 }
@@ -29,11 +33,37 @@ func init() {
 	solver.RegisterHint(GetHints()...)
 }
 
-type glvParams struct {
-	lambda, order big.Int
-	glvBasis      ecc.Lattice
+// constantTimeHints selects the constant-time lifting and half-GCD in the
+// Z[√−2] decomposition hints.
+var constantTimeHints atomic.Bool
+
+// UseConstantTimeHints selects whether the Z[√−2] decomposition hints and
+// glvDecompose use the constant-time scalar lifting and half-GCD of zz2
+// (true) or the faster variable-time ones (false, the default). Provers
+// handling secret scalars should enable it.
+//
+// Only the lifting and the half-GCD are constant-time. The hints still leak
+// through variable-time big.Int code:
+//   - the solver passes the secret scalar as a big.Int, and
+//     zz2.FixedInt.SetBigInt reads as many words as its normalised length;
+//   - glvDecompose reduces the scalar modulo r with big.Int;
+//   - decomposeZZ2ConstantTime and liftZZ2 convert the outputs back with
+//     zz2.FixedComplexNumber.ComplexNumber and check them with
+//     zz2.CheckBound or zz2.CheckLiftBound, whose costs depend on the sizes
+//     of the components;
+//   - the outputs are then split into magnitudes and signs with big.Int.
+//
+// A HintCache does not memoise anything while it is enabled.
+func UseConstantTimeHints(enable bool) {
+	constantTimeHints.Store(enable)
 }
 
+// halfGCD takes a scalar s and the order r and outputs |s1|, |s2| and their
+// sign bits (1 if negative) such that
+//
+//	s1 + s * s2 == 0 mod r
+//
+// with s2 ≠ 0 and |s1|, |s2| < 2·√r. The scalar may be larger than r.
 func halfGCD(mod *big.Int, inputs, outputs []*big.Int) error {
 	if len(inputs) != 2 {
 		return errors.New("expecting two inputs")
@@ -41,232 +71,702 @@ func halfGCD(mod *big.Int, inputs, outputs []*big.Int) error {
 	if len(outputs) != 4 {
 		return errors.New("expecting four outputs")
 	}
-	glvBasis := new(ecc.Lattice)
-	ecc.PrecomputeLattice(inputs[1], inputs[0], glvBasis)
-	outputs[0].Set(&glvBasis.V1[0])
-	outputs[1].Set(&glvBasis.V1[1])
+	s := new(big.Int).Mod(inputs[0], inputs[1])
+	var s1, s2 big.Int
+	if s.Sign() == 0 {
+		// the lattice is degenerate, (0, 1) is its shortest vector
+		s2.SetUint64(1)
+	} else {
+		glvBasis := new(ecc.Lattice)
+		ecc.PrecomputeLattice(inputs[1], s, glvBasis)
+		s1.Set(&glvBasis.V1[0])
+		s2.Set(&glvBasis.V1[1])
+	}
+	for i, c := range []*big.Int{&s1, &s2} {
+		outputs[i].Abs(c)
+		outputs[2+i].SetUint64(0)
+		if c.Sign() == -1 {
+			outputs[2+i].SetUint64(1)
+		}
+	}
+	return nil
+}
 
-	// figure out how many times we have overflowed
-	// s2 * s + s1 = k*r
-	outputs[3].Mul(outputs[1], inputs[0]).
-		Add(outputs[3], outputs[0]).
-		Div(outputs[3], inputs[1])
+// glvDecompose takes a scalar s and λ and outputs |s1|, |s2| and their sign
+// bits (1 if negative) such that
+//
+//	s1 + λ * s2 == s mod r
+//
+// with |s1|, |s2| ≤ zz2.LiftBound(r) = ⌊√r⌋, where s1 + s2*j is the short
+// representative of s modulo the prime ideal generator π in Z[√−2]. The
+// scalar may be larger than r.
+func glvDecompose(mod *big.Int, inputs, outputs []*big.Int) error {
+	if len(inputs) != 2 {
+		return errors.New("expecting two inputs")
+	}
+	if len(outputs) != 4 {
+		return errors.New("expecting four outputs")
+	}
+	// the efficient endomorphism exists on Bandersnatch only
+	params, err := endomorphismParams(mod)
+	if err != nil {
+		return err
+	}
+	if inputs[1].Cmp(params.Lambda) != 0 {
+		return errors.New("unexpected eigenvalue λ")
+	}
+	// the circuits range-check the outputs on r.BitLen()/2 + 1 bits, which
+	// the bound of the lifting implies.
+	k, err := liftZZ2(new(big.Int).Mod(inputs[0], params.Order), params)
+	if err != nil {
+		return fmt.Errorf("glvDecompose: %w", err)
+	}
+	for i, c := range []*big.Int{k.A0, k.A1} {
+		outputs[i].Abs(c)
+		outputs[2+i].SetUint64(0)
+		if c.Sign() == -1 {
+			outputs[2+i].SetUint64(1)
+		}
+	}
+	return nil
+}
 
-	outputs[2].SetUint64(0)
-	if outputs[1].Sign() == -1 {
-		outputs[1].Neg(outputs[1])
-		outputs[2].SetUint64(1)
+// liftZZ2 returns the short representative of the scalar modulo the prime
+// ideal generator of params, with the constant-time lifting if selected, and
+// checks it against zz2.LiftBound. The scalar must be in [0, 2^255).
+func liftZZ2(scalar *big.Int, params *curveParams) (*zz2.ComplexNumber, error) {
+	var z *zz2.ComplexNumber
+	if constantTimeHints.Load() {
+		var sFixed zz2.FixedInt
+		var piFixed, zFixed zz2.FixedComplexNumber
+		if _, err := sFixed.SetBigInt(scalar); err != nil {
+			return nil, err
+		}
+		if _, err := piFixed.SetComplexNumber(params.Pi); err != nil {
+			return nil, err
+		}
+		z = zFixed.LiftConstantTime(&sFixed, &piFixed).ComplexNumber()
+	} else {
+		z = new(zz2.ComplexNumber).Lift(scalar, params.Pi)
 	}
+	return z, zz2.CheckLiftBound(z, zz2.LiftBound(params.Order))
+}
 
+// jointDecompose takes two scalars a, b and the order r and outputs |u1|,
+// |u2|, |v| and their sign bits (1 if negative) such that
+//
+//	u1 + a * v == 0 mod r
+//	u2 + b * v == 0 mod r
+//
+// with v ≠ 0 and |u1|, |u2|, |v| < 2^jointDecomposeBits(r) ≈ r^(2/3), using
+// the LLL reduction of the 3-dimensional lattice of these vectors. The
+// scalars may be larger than r.
+func jointDecompose(mod *big.Int, inputs, outputs []*big.Int) error {
+	if len(inputs) != 3 {
+		return errors.New("expecting three inputs")
+	}
+	if len(outputs) != 6 {
+		return errors.New("expecting six outputs")
+	}
+	r := inputs[2]
+	if r.Sign() <= 0 {
+		return errors.New("jointDecompose: the order must be positive")
+	}
+	k, err := multiDecompose(inputs[:2], r)
+	if err != nil {
+		return fmt.Errorf("jointDecompose: %w", err)
+	}
+	setSigned(outputs, k)
 	return nil
 }
 
-func scalarMulHint(field *big.Int, inputs []*big.Int, outputs []*big.Int) error {
-	if len(inputs) != 4 {
-		return errors.New("expecting four inputs")
+// msmDecompose takes N ≥ 1 scalars s_1, ..., s_N and the order r and outputs
+// |u_1|, ..., |u_N|, |v| and their sign bits (1 if negative) such that
+//
+//	u_i + s_i * v == 0 mod r for all i
+//
+// with v ≠ 0 and all the values smaller than 2^multiDecomposeBits(r, N) ≈
+// r^(N/(N+1)), using the LLL reduction of the (N+1)-dimensional lattice of
+// these vectors. It is jointDecompose for any number of scalars, which may be
+// larger than r.
+func msmDecompose(mod *big.Int, inputs, outputs []*big.Int) error {
+	if len(inputs) < 2 {
+		return errors.New("expecting at least two inputs")
 	}
-	if len(outputs) != 2 {
-		return errors.New("expecting two outputs")
+	if len(outputs) != 2*len(inputs) {
+		return fmt.Errorf("expecting %d outputs", 2*len(inputs))
 	}
-	// compute the resulting point [s]Q
-	if field.Cmp(ecc.BLS12_381.ScalarField()) == 0 {
-		order, _ := new(big.Int).SetString("13108968793781547619861935127046491459309155893440570251786403306729687672801", 10)
-		if inputs[3].Cmp(order) == 0 {
-			var P bandersnatch.PointAffine
-			P.X.SetBigInt(inputs[0])
-			P.Y.SetBigInt(inputs[1])
-			P.ScalarMultiplication(&P, inputs[2])
-			P.X.BigInt(outputs[0])
-			P.Y.BigInt(outputs[1])
-		} else {
-			var P jubjub.PointAffine
-			P.X.SetBigInt(inputs[0])
-			P.Y.SetBigInt(inputs[1])
-			P.ScalarMultiplication(&P, inputs[2])
-			P.X.BigInt(outputs[0])
-			P.Y.BigInt(outputs[1])
-		}
-	} else {
-		return errors.New("scalarMulHint: curve not supported")
+	r := inputs[len(inputs)-1]
+	if r.Sign() <= 0 {
+		return errors.New("msmDecompose: the order must be positive")
+	}
+	k, err := multiDecompose(inputs[:len(inputs)-1], r)
+	if err != nil {
+		return fmt.Errorf("msmDecompose: %w", err)
 	}
+	setSigned(outputs, k)
 	return nil
 }
 
-func halfGCDZZ2(mod *big.Int, inputs, outputs []*big.Int) error {
-	if len(inputs) != 2 {
-		return errors.New("expecting two input")
+// multiDecompose returns the first vector (u_1, ..., u_N, v) of an
+// LLL-reduced basis of the lattice {u_i + s_i * v == 0 mod r for all i}, after
+// checking that v ≠ 0 and that the values fit in multiDecomposeBits(r, N)
+// bits.
+func multiDecompose(scalars []*big.Int, r *big.Int) ([]*big.Int, error) {
+	s := make([]*big.Int, len(scalars))
+	for i := range s {
+		s[i] = new(big.Int).Mod(scalars[i], r)
 	}
-	if len(outputs) != 4 {
-		return errors.New("expecting four outputs")
+	basis := multiBasis(s, r)
+	lll(basis)
+	// within the bound the reduced vector is shorter than r, so that v = 0
+	// would imply u_i = 0 for all i
+	k := basis[0]
+	if k[len(s)].Sign() == 0 {
+		return nil, errors.New("degenerate decomposition")
 	}
-	// the efficient endomorphism exists on Bandersnatch only
-	if mod.Cmp(ecc.BLS12_381.ScalarField()) != 0 {
-		return errors.New("no efficient endomorphism is available on this curve")
-	}
-	var glv glvParams
-	var init sync.Once
-	init.Do(func() {
-		glv.lambda.SetString("8913659658109529928382530854484400854125314752504019737736543920008458395397", 10)
-		glv.order.SetString("13108968793781547619861935127046491459309155893440570251786403306729687672801", 10)
-		ecc.PrecomputeLattice(&glv.order, &glv.lambda, &glv.glvBasis)
-	})
-
-	glvBasis := new(ecc.Lattice)
-	ecc.PrecomputeLattice(&glv.order, inputs[1], glvBasis)
-	r := zz2.ComplexNumber{
-		A0: &glvBasis.V1[0],
-		A1: &glvBasis.V1[1],
-	}
-	sp := ecc.SplitScalar(inputs[0], glvBasis)
-	// in-circuit we check that Q - [s]P = 0 or equivalently Q + [-s]P = 0
-	// so here we return -s instead of s.
-	s := zz2.ComplexNumber{
-		A0: &sp[0],
-		A1: &sp[1],
+	n := multiDecomposeBits(r, len(s))
+	for i := range k {
+		if k[i].BitLen() > n {
+			// the circuits range-check the outputs on n bits
+			return nil, errors.New("decomposition out of bounds")
+		}
 	}
-	s.Neg(&s)
-	res := zz2.HalfGCD(&r, &s)
-	outputs[0].Set(res[0].A0)
-	outputs[1].Set(res[0].A1)
-	outputs[2].Set(res[1].A0)
-	outputs[3].Set(res[1].A1)
+	return k, nil
+}
 
-	if outputs[0].Sign() == -1 {
-		outputs[0].Neg(outputs[0])
+// jointDecomposeGLV takes two scalars a, b and λ and outputs, for the
+// Bandersnatch curve, the magnitudes of u1, u2, u3, u4, v1, v2 followed by
+// their sign bits (1 if negative) such that
+//
+//	u1 + λ * u2 + a * (v1 + λ * v2) == 0 mod r
+//	u3 + λ * u4 + b * (v1 + λ * v2) == 0 mod r
+//
+// with (v1, v2) ≠ (0, 0) and all the values smaller than
+// 2^jointDecomposeGLVBits(r) ≈ r^(1/3), using the LLL reduction of the
+// 6-dimensional lattice of these vectors. The scalars may be larger than r.
+func jointDecomposeGLV(mod *big.Int, inputs, outputs []*big.Int) error {
+	if len(inputs) != 3 {
+		return errors.New("expecting three inputs")
 	}
-	if outputs[1].Sign() == -1 {
-		outputs[1].Neg(outputs[1])
+	if len(outputs) != 12 {
+		return errors.New("expecting twelve outputs")
 	}
-	if outputs[2].Sign() == -1 {
-		outputs[2].Neg(outputs[2])
+	k, err := multiDecomposeGLV(mod, inputs[:2], inputs[2])
+	if err != nil {
+		return fmt.Errorf("jointDecomposeGLV: %w", err)
 	}
-	if outputs[3].Sign() == -1 {
-		outputs[3].Neg(outputs[3])
+	setSigned(outputs, k)
+	return nil
+}
+
+// msmDecomposeGLV takes N ≥ 1 scalars s_1, ..., s_N and λ and outputs, for
+// the Bandersnatch curve, the magnitudes of u_1,1, u_1,2, ..., u_N,1, u_N,2,
+// v1, v2 followed by their sign bits (1 if negative) such that
+//
+//	u_i,1 + λ * u_i,2 + s_i * (v1 + λ * v2) == 0 mod r for all i
+//
+// with (v1, v2) ≠ (0, 0) and all the values smaller than
+// 2^multiDecomposeGLVBits(r, N) ≈ r^(N/(2N+2)), using the LLL reduction of
+// the (2N+2)-dimensional lattice of these vectors. It is jointDecomposeGLV
+// for any number of scalars, which may be larger than r.
+func msmDecomposeGLV(mod *big.Int, inputs, outputs []*big.Int) error {
+	if len(inputs) < 2 {
+		return errors.New("expecting at least two inputs")
 	}
+	if len(outputs) != 4*len(inputs) {
+		return fmt.Errorf("expecting %d outputs", 4*len(inputs))
+	}
+	k, err := multiDecomposeGLV(mod, inputs[:len(inputs)-1], inputs[len(inputs)-1])
+	if err != nil {
+		return fmt.Errorf("msmDecomposeGLV: %w", err)
+	}
+	setSigned(outputs, k)
 	return nil
 }
 
-func halfGCDZZ2Emulated(nativeMod *big.Int, nativeInputs, nativeOutputs []*big.Int) error {
-	return emulated.UnwrapHintWithNativeInput(nativeInputs, nativeOutputs, func(nnMod *big.Int, nninputs, nnOutputs []*big.Int) error {
-		if len(nninputs) != 2 {
-			return errors.New("expecting two input")
+// multiDecomposeGLV returns the first vector (u_1,1, u_1,2, ..., u_N,1,
+// u_N,2, v1, v2) of an LLL-reduced basis of the lattice
+// {u_i,1 + λ*u_i,2 + s_i*(v1 + λ*v2) == 0 mod r for all i} of Bandersnatch,
+// after checking λ, that (v1, v2) ≠ (0, 0) and that the values fit in
+// multiDecomposeGLVBits(r, N) bits.
+func multiDecomposeGLV(mod *big.Int, scalars []*big.Int, lambda *big.Int) ([]*big.Int, error) {
+	// the efficient endomorphism exists on Bandersnatch only
+	params, err := endomorphismParams(mod)
+	if err != nil {
+		return nil, err
+	}
+	if lambda.Cmp(params.Lambda) != 0 {
+		return nil, errors.New("unexpected eigenvalue λ")
+	}
+	r := params.Order
+	s := make([]*big.Int, len(scalars))
+	for i := range s {
+		s[i] = new(big.Int).Mod(scalars[i], r)
+	}
+	d := 2*len(s) + 2
+	basis := multiBasisGLV(s, params)
+	lll(basis)
+
+	// within the bound the reduced vector is shorter than the GLV lattice, so
+	// that v1 = v2 = 0 would imply u_i,1 = u_i,2 = 0 for all i
+	k := basis[0]
+	if k[d-2].Sign() == 0 && k[d-1].Sign() == 0 {
+		return nil, errors.New("degenerate decomposition")
+	}
+	n := multiDecomposeGLVBits(r, len(scalars))
+	for i := range k {
+		if k[i].BitLen() > n {
+			// the circuits range-check the outputs on n bits
+			return nil, errors.New("decomposition out of bounds")
 		}
-		if len(nnOutputs) != 4 {
-			return errors.New("expecting four outputs")
+	}
+	return k, nil
+}
+
+// multiBasisGLV returns a basis of the lattice {(u_1,1, u_1,2, ...,
+// u_N,1, u_N,2, v1, v2) : u_i,1 + λ*u_i,2 + s_i*(v1 + λ*v2) == 0 mod r for
+// all i} of Bandersnatch for 0 ≤ s_i < r. As multiBasis, it lifts the
+// reduced basis (x1, x2, y1, y2) of the lattice of s_1 with, for each i ≥ 2,
+// the short representative (z1, z2) of -s_i*(y1 + λ*y2) mod r given by the
+// GLV lattice, and completes it with the GLV lattice at the pairs i ≥ 2.
+func multiBasisGLV(s []*big.Int, params *curveParams) [][]*big.Int {
+	r, l := params.Order, params.Lattice
+	d := 2*len(s) + 2
+	basis := make([][]*big.Int, d)
+	for i := range basis {
+		basis[i] = make([]*big.Int, d)
+		for j := range basis[i] {
+			basis[i][j] = new(big.Int)
 		}
-		// the efficient endomorphism exists on Bandersnatch only
-		if nativeMod.Cmp(ecc.BLS12_381.ScalarField()) != 0 {
-			return errors.New("no efficient endomorphism is available on this curve")
+	}
+
+	// the 4-dimensional lattice of s_1, with (r, 0) in the GLV lattice
+	first := [][]*big.Int{
+		{new(big.Int).Set(&l.V1[0]), new(big.Int).Set(&l.V1[1]), big.NewInt(0), big.NewInt(0)},
+		{new(big.Int).Set(&l.V2[0]), new(big.Int).Set(&l.V2[1]), big.NewInt(0), big.NewInt(0)},
+		{new(big.Int).Neg(s[0]), big.NewInt(0), big.NewInt(1), big.NewInt(0)},
+		{new(big.Int).Neg(new(big.Int).Mod(new(big.Int).Mul(s[0], params.Lambda), r)), big.NewInt(0), big.NewInt(0), big.NewInt(1)},
+	}
+	lll(first)
+	var y big.Int
+	for k, e := range first {
+		basis[k][0].Set(e[0])
+		basis[k][1].Set(e[1])
+		basis[k][d-2].Set(e[2])
+		basis[k][d-1].Set(e[3])
+		// y = -(y1 + λ*y2) mod r
+		y.Mul(e[3], params.Lambda).Add(&y, e[2]).Neg(&y).Mod(&y, r)
+		for i := 1; i < len(s); i++ {
+			var z big.Int
+			z.Mul(&y, s[i]).Mod(&z, r)
+			short := ecc.SplitScalar(&z, l)
+			basis[k][2*i].Set(&short[0])
+			basis[k][2*i+1].Set(&short[1])
 		}
-		var glv glvParams
-		var init sync.Once
-		init.Do(func() {
-			glv.lambda.SetString("8913659658109529928382530854484400854125314752504019737736543920008458395397", 10)
-			glv.order.SetString("13108968793781547619861935127046491459309155893440570251786403306729687672801", 10)
-			ecc.PrecomputeLattice(&glv.order, &glv.lambda, &glv.glvBasis)
-		})
+	}
+	for i := 1; i < len(s); i++ {
+		row := basis[2+2*i]
+		row[2*i].Set(&l.V1[0])
+		row[2*i+1].Set(&l.V1[1])
+		row = basis[3+2*i]
+		row[2*i].Set(&l.V2[0])
+		row[2*i+1].Set(&l.V2[1])
+	}
+	return basis
+}
+
+// setSigned sets outputs to the magnitudes of the values followed by their
+// sign bits (1 if negative).
+func setSigned(outputs, values []*big.Int) {
+	for i, x := range values {
+		outputs[i].Abs(x)
+		outputs[len(values)+i].SetUint64(0)
+		if x.Sign() == -1 {
+			outputs[len(values)+i].SetUint64(1)
+		}
+	}
+}
+
+// jointDecomposeGLVBits returns the bound on the bit length of the outputs of
+// jointDecomposeGLV, see multiDecomposeGLVBits: the first vector is shorter
+// than 1.46 * r^(1/3).
+func jointDecomposeGLVBits(r *big.Int) int {
+	return multiDecomposeGLVBits(r, 2)
+}
+
+// multiDecomposeGLVBits returns the bound on the bit length of the outputs of
+// msmDecomposeGLV for N scalars. The lattice has dimension d = 2N+2 and
+// determinant r^N, so that the first vector of an LLL-reduced basis
+// (δ = 99/100) is shorter than (100/74)^((d-1)/4) * r^(N/d), i.e. than
+// 2^(0.109*(2N+1)) * r^(N/(2N+2)).
+func multiDecomposeGLVBits(r *big.Int, n int) int {
+	return (n*r.BitLen()+2*n+1)/(2*n+2) + (2*n+1)/9 + 1
+}
 
+// multiBasis returns a basis of the lattice {(u_1, ..., u_N, v) :
+// u_i + s_i*v == 0 mod r for all i} for 0 ≤ s_i < r. It lifts the reduced
+// basis (x, y) of {(u_1, v) : u_1 + s_1*v == 0 mod r} to
+// (x, -s_2*y mod r, ..., -s_N*y mod r, y) and completes it with r*e_i for
+// i ≥ 2, which is much closer to a reduced basis than the triangular one and
+// saves most of the LLL iterations.
+func multiBasis(s []*big.Int, r *big.Int) [][]*big.Int {
+	var lattice [2][2]*big.Int
+	if s[0].Sign() == 0 {
+		lattice = [2][2]*big.Int{{new(big.Int).Set(r), big.NewInt(0)}, {big.NewInt(0), big.NewInt(1)}}
+	} else {
 		glvBasis := new(ecc.Lattice)
-		ecc.PrecomputeLattice(&glv.order, nninputs[1], glvBasis)
-		r := zz2.ComplexNumber{
-			A0: &glvBasis.V1[0],
-			A1: &glvBasis.V1[1],
+		ecc.PrecomputeLattice(r, s[0], glvBasis)
+		// x + s_1*y == 0 mod r
+		lattice = [2][2]*big.Int{
+			{&glvBasis.V1[0], &glvBasis.V1[1]},
+			{&glvBasis.V2[0], &glvBasis.V2[1]},
 		}
-		sp := ecc.SplitScalar(nninputs[0], glvBasis)
-		// in-circuit we check that Q - [s]P = 0 or equivalently Q + [-s]P = 0
-		// so here we return -s instead of s.
-		s := zz2.ComplexNumber{
-			A0: &sp[0],
-			A1: &sp[1],
+	}
+	d := len(s) + 1
+	halfR := new(big.Int).Rsh(r, 1)
+	basis := make([][]*big.Int, d)
+	for i, e := range lattice {
+		basis[i] = make([]*big.Int, d)
+		basis[i][0] = e[0]
+		basis[i][d-1] = e[1]
+		for j := 1; j < len(s); j++ {
+			// the centered representative of -s_j*y mod r
+			u := new(big.Int).Mul(s[j], e[1])
+			u.Neg(u).Mod(u, r)
+			if u.Cmp(halfR) > 0 {
+				u.Sub(u, r)
+			}
+			basis[i][j] = u
 		}
-		s.Neg(&s)
-		res := zz2.HalfGCD(&r, &s)
-		nnOutputs[0].Set(res[0].A0)
-		nnOutputs[1].Set(res[0].A1)
-		nnOutputs[2].Set(res[1].A0)
-		nnOutputs[3].Set(res[1].A1)
+	}
+	for j := 1; j < len(s); j++ {
+		basis[1+j] = make([]*big.Int, d)
+		for k := range basis[1+j] {
+			basis[1+j][k] = new(big.Int)
+		}
+		basis[1+j][j].Set(r)
+	}
+	return basis
+}
 
-		return nil
-	})
+// jointDecomposeBits returns the bound on the bit length of the outputs of
+// jointDecompose, see multiDecomposeBits: the first vector is shorter than
+// 1.17 * r^(2/3).
+func jointDecomposeBits(r *big.Int) int {
+	return multiDecomposeBits(r, 2)
 }
 
-func halfGCDZZ2Signs(mod *big.Int, inputs, outputs []*big.Int) error {
+// multiDecomposeBits returns the bound on the bit length of the outputs of
+// msmDecompose for N scalars. The lattice has dimension d = N+1 and
+// determinant r^N, so that the first vector of an LLL-reduced basis
+// (δ = 99/100) is shorter than (100/74)^((d-1)/4) * r^(N/d), i.e. than
+// 2^(0.109*N) * r^(N/(N+1)).
+func multiDecomposeBits(r *big.Int, n int) int {
+	return (n*r.BitLen()+n)/(n+1) + n/9 + 1
+}
+
+// lll reduces the rows of the basis b in place with the LLL algorithm for
+// δ = 99/100, in exact rational arithmetic. It is meant for the small
+// dimensions of the decomposition hints, and expects independent rows.
+func lll(b [][]*big.Int) {
+	delta := big.NewRat(99, 100)
+	norms, mu := gramSchmidt(b)
+	for k := 1; k < len(b); {
+		sizeReduce(b, mu, k, k-1)
+		// Lovász condition B_k ≥ (δ - μ_{k,k-1}²) B_{k-1}
+		m := new(big.Rat).Set(mu[k][k-1])
+		mm := new(big.Rat).Mul(m, m)
+		rhs := new(big.Rat).Sub(delta, mm)
+		rhs.Mul(rhs, norms[k-1])
+		if norms[k].Cmp(rhs) >= 0 {
+			for j := k - 2; j >= 0; j-- {
+				sizeReduce(b, mu, k, j)
+			}
+			k++
+			continue
+		}
+
+		// swap b_k and b_{k-1} and update the Gram-Schmidt data
+		b[k], b[k-1] = b[k-1], b[k]
+		norm := new(big.Rat).Mul(mm, norms[k-1])
+		norm.Add(norm, norms[k])
+		mu[k][k-1].Mul(m, norms[k-1]).Quo(mu[k][k-1], norm)
+		norms[k].Mul(norms[k], norms[k-1]).Quo(norms[k], norm)
+		norms[k-1] = norm
+		for j := 0; j < k-1; j++ {
+			mu[k][j], mu[k-1][j] = mu[k-1][j], mu[k][j]
+		}
+		for i := k + 1; i < len(b); i++ {
+			t := mu[i][k]
+			mu[i][k] = new(big.Rat).Sub(mu[i][k-1], new(big.Rat).Mul(m, t))
+			mu[i][k-1] = new(big.Rat).Add(t, new(big.Rat).Mul(mu[k][k-1], mu[i][k]))
+		}
+		k = max(k-1, 1)
+	}
+}
+
+// sizeReduce subtracts the multiple of b_l nearest to the projection of b_k on
+// b*_l from b_k, l < k, and updates the coefficients μ_{k,j}.
+func sizeReduce(b [][]*big.Int, mu [][]*big.Rat, k, l int) {
+	q := roundRat(mu[k][l])
+	if q.Sign() == 0 {
+		return
+	}
+	for i := range b[k] {
+		b[k][i].Sub(b[k][i], new(big.Int).Mul(q, b[l][i]))
+	}
+	qr := new(big.Rat).SetInt(q)
+	mu[k][l].Sub(mu[k][l], qr)
+	for j := 0; j < l; j++ {
+		mu[k][j].Sub(mu[k][j], new(big.Rat).Mul(qr, mu[l][j]))
+	}
+}
+
+// gramSchmidt returns the squared norms B_i = <b*_i, b*_i> of the
+// Gram-Schmidt orthogonalisation b* of the rows of b and the coefficients
+// μ_{i,j} = <b_i, b*_j> / B_j for j < i.
+func gramSchmidt(b [][]*big.Int) (norms []*big.Rat, mu [][]*big.Rat) {
+	bs := make([][]*big.Rat, len(b))
+	norms = make([]*big.Rat, len(b))
+	mu = make([][]*big.Rat, len(b))
+	for i := range b {
+		bi := make([]*big.Rat, len(b[i]))
+		for k := range b[i] {
+			bi[k] = new(big.Rat).SetInt(b[i][k])
+		}
+		bs[i] = append([]*big.Rat{}, bi...)
+		mu[i] = make([]*big.Rat, i)
+		for j := 0; j < i; j++ {
+			mu[i][j] = new(big.Rat).Quo(dotRat(bi, bs[j]), norms[j])
+			for k := range bs[i] {
+				bs[i][k] = new(big.Rat).Sub(bs[i][k], new(big.Rat).Mul(mu[i][j], bs[j][k]))
+			}
+		}
+		norms[i] = dotRat(bs[i], bs[i])
+	}
+	return norms, mu
+}
+
+// dotRat returns the inner product of x and y.
+func dotRat(x, y []*big.Rat) *big.Rat {
+	res := new(big.Rat)
+	for i := range x {
+		res.Add(res, new(big.Rat).Mul(x[i], y[i]))
+	}
+	return res
+}
+
+// roundRat returns the integer nearest to x, rounding halves up.
+func roundRat(x *big.Rat) *big.Int {
+	// ⌊(2 * num + den) / (2 * den)⌋
+	num := new(big.Int).Lsh(x.Num(), 1)
+	num.Add(num, x.Denom())
+	den := new(big.Int).Lsh(x.Denom(), 1)
+	return num.Div(num, den)
+}
+
+// scalarMulHint takes the coordinates of a point P, a scalar s and the
+// twistededwards.ID of the curve, and outputs the coordinates of [s]P.
+func scalarMulHint(field *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	if len(inputs) != 4 {
+		return errors.New("expecting four inputs")
+	}
+	if len(outputs) != 2 {
+		return errors.New("expecting two outputs")
+	}
+	if !inputs[3].IsUint64() || inputs[3].Uint64() > 0xff {
+		return errors.New("scalarMulHint: unknown twisted edwards curve id")
+	}
+	id := twistededwards.ID(inputs[3].Uint64())
+	params, err := getCurveParams(id)
+	if err != nil {
+		return fmt.Errorf("scalarMulHint: %w", err)
+	}
+	if field.Cmp(params.Field) != 0 {
+		return errors.New("scalarMulHint: the curve is not defined over the SNARK field")
+	}
+	// compute the resulting point [s]P
+	return scalarMulNative(id, inputs[0], inputs[1], inputs[2], outputs[0], outputs[1])
+}
+
+// Output layout of the halfGCDZZ2Combined hint.
+const (
+	// |u1|, |u2|, |v1|, |v2|
+	zz2MagnitudesOffset = 0
+	// 1 if the corresponding value is negative, 0 otherwise
+	zz2SignsOffset = 4
+	zz2NbOutputs   = 8
+)
+
+// halfGCDZZ2Combined takes a scalar s and λ and outputs, in one pass, the
+// magnitudes and sign bits of u1, u2, v1, v2 such that
+//
+//	u1 + λ * u2 + s * (v1 + λ * v2) == 0 mod r
+//
+// with (v1, v2) ≠ (0, 0), see the zz2*Offset constants for the layout.
+func halfGCDZZ2Combined(mod *big.Int, inputs, outputs []*big.Int) error {
 	if len(inputs) != 2 {
 		return errors.New("expecting two input")
 	}
-	if len(outputs) != 4 {
-		return errors.New("expecting four outputs")
+	if len(outputs) != zz2NbOutputs {
+		return errors.New("expecting eight outputs")
 	}
 	// the efficient endomorphism exists on Bandersnatch only
-	if mod.Cmp(ecc.BLS12_381.ScalarField()) != 0 {
-		return errors.New("no efficient endomorphism is available on this curve")
+	params, err := endomorphismParams(mod)
+	if err != nil {
+		return err
 	}
-	var glv glvParams
-	var init sync.Once
-	init.Do(func() {
-		glv.lambda.SetString("8913659658109529928382530854484400854125314752504019737736543920008458395397", 10)
-		glv.order.SetString("13108968793781547619861935127046491459309155893440570251786403306729687672801", 10)
-		ecc.PrecomputeLattice(&glv.order, &glv.lambda, &glv.glvBasis)
-	})
-
-	glvBasis := new(ecc.Lattice)
-	ecc.PrecomputeLattice(&glv.order, inputs[1], glvBasis)
-	r := zz2.ComplexNumber{
-		A0: &glvBasis.V1[0],
-		A1: &glvBasis.V1[1],
+	if inputs[1].Cmp(params.Lambda) != 0 {
+		return errors.New("unexpected eigenvalue λ")
+	}
+	res, err := decomposeZZ2(inputs[0], params)
+	if err != nil {
+		return err
 	}
-	sp := ecc.SplitScalar(inputs[0], glvBasis)
-	s := zz2.ComplexNumber{
-		A0: &sp[0],
-		A1: &sp[1],
+	for i, c := range []*big.Int{res[0].A0, res[0].A1, res[1].A0, res[1].A1} {
+		outputs[zz2MagnitudesOffset+i].Abs(c)
+		outputs[zz2SignsOffset+i].SetUint64(0)
+		if c.Sign() == -1 {
+			outputs[zz2SignsOffset+i].SetUint64(1)
+		}
 	}
-	s.Neg(&s)
-	res := zz2.HalfGCD(&r, &s)
+	return nil
+}
 
-	outputs[0].SetUint64(0)
-	outputs[1].SetUint64(0)
-	outputs[2].SetUint64(0)
-	outputs[3].SetUint64(0)
+// decomposeZZ2 returns the half-GCD (w, v, u) of π and -s, where π generates
+// the prime ideal (r, j - λ) above the order r of the curve and s is the
+// short representative of the scalar modulo π. Then w = -s*v mod π, i.e.
+//
+//	u1 + λ*u2 + s*(v1 + λ*v2) == 0 mod r
+//
+// with w = u1 + u2*j and v = v1 + v2*j.
+func decomposeZZ2(scalar *big.Int, params *curveParams) ([3]*zz2.ComplexNumber, error) {
+	if constantTimeHints.Load() {
+		return decomposeZZ2ConstantTime(scalar, params.Pi, params.Order)
+	}
+	// in-circuit we check that Q - [s]P = 0 or equivalently Q + [-s]P = 0
+	// so here we use -s instead of s.
+	var s zz2.ComplexNumber
+	s.Lift(scalar, params.Pi).Neg(&s)
+	// the circuits range-check the outputs against a looser bound, so that
+	// a bad decomposition is reported here rather than as an unsatisfied
+	// ToBinary constraint.
+	return zz2.HalfGCDAutoBounded(params.Pi, &s, zz2.HalfGCDBound(params.Order))
+}
+
+// decomposeZZ2ConstantTime is the constant-time counterpart of decomposeZZ2
+// for the prime ideal generator pi of norm order.
+func decomposeZZ2ConstantTime(scalar *big.Int, pi *zz2.ComplexNumber, order *big.Int) ([3]*zz2.ComplexNumber, error) {
+	var sFixed zz2.FixedInt
+	var piFixed, s zz2.FixedComplexNumber
+	if _, err := sFixed.SetBigInt(scalar); err != nil {
+		return [3]*zz2.ComplexNumber{}, err
+	}
+	if _, err := piFixed.SetComplexNumber(pi); err != nil {
+		return [3]*zz2.ComplexNumber{}, err
+	}
+	// N(s) < N(π) = r after lifting.
+	s.LiftConstantTime(&sFixed, &piFixed).Neg(&s)
+	res := zz2.HalfGCDConstantTime(&piFixed, &s, order.BitLen())
+	out := [3]*zz2.ComplexNumber{
+		res[0].ComplexNumber(),
+		res[1].ComplexNumber(),
+		res[2].ComplexNumber(),
+	}
+	return out, zz2.CheckBound(out, zz2.HalfGCDBound(order))
+}
 
-	if res[0].A0.Sign() == -1 {
-		outputs[0].SetUint64(1)
+// halfGCDZZ2Batch is the batch counterpart of halfGCDZZ2Combined. It takes λ
+// followed by N scalars and outputs, for the i-th scalar, the eight outputs of
+// halfGCDZZ2Combined at zz2NbOutputs*i. The half-GCDs run in parallel with
+// zz2.BatchHalfGCD, unless the constant-time hints are selected.
+func halfGCDZZ2Batch(mod *big.Int, inputs, outputs []*big.Int) error {
+	if len(inputs) < 2 {
+		return errors.New("expecting at least two inputs")
+	}
+	if len(outputs) != zz2NbOutputs*(len(inputs)-1) {
+		return errors.New("expecting eight outputs per scalar")
 	}
-	if res[0].A1.Sign() == -1 {
-		outputs[1].SetUint64(1)
+	// the efficient endomorphism exists on Bandersnatch only
+	params, err := endomorphismParams(mod)
+	if err != nil {
+		return err
 	}
-	if res[1].A0.Sign() == -1 {
-		outputs[2].SetUint64(1)
+	if inputs[0].Cmp(params.Lambda) != 0 {
+		return errors.New("unexpected eigenvalue λ")
+	}
+	scalars := inputs[1:]
+	res := make([][3]*zz2.ComplexNumber, len(scalars))
+	if constantTimeHints.Load() {
+		for i, scalar := range scalars {
+			if res[i], err = decomposeZZ2ConstantTime(scalar, params.Pi, params.Order); err != nil {
+				return err
+			}
+		}
+	} else {
+		pairs := make([][2]*zz2.ComplexNumber, len(scalars))
+		for i, scalar := range scalars {
+			// -s as in decomposeZZ2
+			var s zz2.ComplexNumber
+			s.Lift(scalar, params.Pi).Neg(&s)
+			pairs[i] = [2]*zz2.ComplexNumber{params.Pi, &s}
+		}
+		res = zz2.BatchHalfGCD(pairs, 0)
 	}
-	if res[1].A1.Sign() == -1 {
-		outputs[3].SetUint64(1)
+	bound := zz2.HalfGCDBound(params.Order)
+	for i := range res {
+		if err := zz2.CheckBound(res[i], bound); err != nil {
+			return fmt.Errorf("halfGCDZZ2Batch: scalar %d: %w", i, err)
+		}
+		out := outputs[zz2NbOutputs*i : zz2NbOutputs*(i+1)]
+		for j, c := range []*big.Int{res[i][0].A0, res[i][0].A1, res[i][1].A0, res[i][1].A1} {
+			out[zz2MagnitudesOffset+j].Abs(c)
+			out[zz2SignsOffset+j].SetUint64(0)
+			if c.Sign() == -1 {
+				out[zz2SignsOffset+j].SetUint64(1)
+			}
+		}
 	}
 	return nil
 }
 
-func checkHalfGCDZZ2(api frontend.API, s, lambda frontend.Variable) {
-	var fr BandersnatchFr
+// checkHalfGCDZZ2 checks, using non-native arithmetic, that the signed
+// decomposition of s given by the magnitudes' bits and the sign bits verifies
+//
+//	u1 + λ * u2 + s * (v1 + λ * v2) == 0 mod r
+//
+// where sBits are the little-endian bits of s, bits[0..3] are the ones of
+// |u1|, |u2|, |v1|, |v2| and signs[0..3] are 1 if the corresponding value is
+// negative. The bits are the ones the caller range-checked and uses in the
+// scalar multiplication, so the relation is checked on the values actually
+// used. The sign bits are constrained to be boolean here.
+//
+// It also checks that (v1, v2) ≠ (0, 0), otherwise the trivial decomposition
+// would verify the relation for any claimed result. As the lattice
+// {(x, y) : x + λy = 0 mod r} has no non-zero vector with components shorter
+// than ≈ √r, this is equivalent to v1 + λ * v2 ≠ 0 mod r for range-checked
+// values.
+func checkHalfGCDZZ2(api frontend.API, sBits []frontend.Variable, lambda frontend.Variable, bits [4][]frontend.Variable, signs [4]frontend.Variable) {
 	sapi, err := emulated.NewField[BandersnatchFr](api)
 	if err != nil {
 		panic(err)
 	}
 
-	// compute the decomposition using a hint. We have to use the emulated
-	// version which takes native input and outputs non-native outputs.
-	//
-	// the hints allow to decompose the scalar s into s1 and s2 such that
-	// 	   u1 + λ * u2 + s * (v1 + λ * v2) == 0 mod r
-	sd, err := sapi.NewHintWithNativeInput(halfGCDZZ2Emulated, 4, s, lambda)
-	if err != nil {
-		panic(err)
+	// |v1| + |v2| ≠ 0
+	api.AssertIsDifferent(api.Add(api.FromBinary(bits[2]...), api.FromBinary(bits[3]...)), 0)
+
+	// u1, u2, v1, v2 as signed nonnative elements
+	var sd [4]*emulated.Element[BandersnatchFr]
+	for i := range sd {
+		api.AssertIsBoolean(signs[i])
+		abs := sapi.FromBits(bits[i]...)
+		sd[i] = sapi.Select(signs[i], sapi.Neg(abs), abs)
 	}
 	// lambda as nonnative element
 	lambdaEmu := sapi.NewElement(lambda)
-	// the scalar as nonnative element. We need to split at 64 bits.
-	limbs, err := api.NewHint(decompose, int(fr.NbLimbs()), s)
-	if err != nil {
-		panic(err)
-	}
-	sEmu := sapi.NewElement(limbs)
+	// the scalar as nonnative element
+	sEmu := sapi.FromBits(sBits...)
 
 	// u1 + λ * u2 + s * (v1 + λ * v2) == 0 mod r
 	lhs := sapi.MulNoReduce(sd[1], lambdaEmu)
@@ -279,39 +779,106 @@ func checkHalfGCDZZ2(api frontend.API, s, lambda frontend.Variable) {
 	sapi.AssertIsEqual(lhs, sapi.Zero())
 }
 
-func decompose(mod *big.Int, inputs, outputs []*big.Int) error {
-	if len(inputs) != 1 && len(outputs) != 4 {
-		return errors.New("input/output length mismatch")
+// checkGLV checks, using non-native arithmetic, that the signed
+// decomposition of s given by the magnitudes' bits and the sign bits verifies
+//
+//	s1 + λ * s2 == s mod r
+//
+// where sBits are the little-endian bits of s. The sign bits are constrained
+// to be boolean here.
+func checkGLV(api frontend.API, sBits []frontend.Variable, lambda frontend.Variable, bits [2][]frontend.Variable, signs [2]frontend.Variable) {
+	sapi, err := emulated.NewField[BandersnatchFr](api)
+	if err != nil {
+		panic(err)
 	}
-	tmp := new(big.Int).Set(inputs[0])
-	mask := new(big.Int).SetUint64(^uint64(0))
-	for i := 0; i < 4; i++ {
-		outputs[i].And(tmp, mask)
-		tmp.Rsh(tmp, 64)
+	var sd [2]*emulated.Element[BandersnatchFr]
+	for i := range sd {
+		api.AssertIsBoolean(signs[i])
+		abs := sapi.FromBits(bits[i]...)
+		sd[i] = sapi.Select(signs[i], sapi.Neg(abs), abs)
 	}
-	return nil
-}
-
-// utils
-type fourLimbPrimeField struct{}
+	lambdaEmu := sapi.NewElement(lambda)
+	sEmu := sapi.FromBits(sBits...)
 
-func (fourLimbPrimeField) NbLimbs() uint     { return 4 }
-func (fourLimbPrimeField) BitsPerLimb() uint { return 64 }
-func (fourLimbPrimeField) IsPrime() bool     { return true }
+	// s1 + λ * s2 == s mod r
+	lhs := sapi.MulNoReduce(sd[1], lambdaEmu)
+	lhs = sapi.Add(lhs, sd[0])
+	sapi.AssertIsEqual(lhs, sEmu)
+}
 
-// BandersnatchFr provides type parametrization for field emulation:
-//   - limbs: 4
-//   - limb width: 64 bits
-//
-// The prime modulus for type parametrisation is:
+// checkHalfGCD checks, using non-native arithmetic mod r, that the signed
+// decomposition given by the magnitudes' bits and the sign bits verifies
 //
-//	0x1cfb69d4ca675f520cce760202687600ff8f87007419047174fd06b52876e7e1 (base 16)
-//	13108968793781547619861935127046491459309155893440570251786403306729687672801 (base 10)
+//	s1 + s * s2 == 0 mod r
 //
-// This is the scalar field of the Bandersnatch curve.
-type BandersnatchFr struct{ fourLimbPrimeField }
+// where sBits are the little-endian bits of s. The sign bits are constrained
+// to be boolean here.
+func checkHalfGCD(api frontend.API, id twistededwards.ID, sBits []frontend.Variable, bits [2][]frontend.Variable, signs [2]frontend.Variable) {
+	switch id {
+	case twistededwards.BN254:
+		checkHalfGCDEmulated[BabyJubjubFr](api, sBits, bits, signs)
+	case twistededwards.BLS12_377:
+		checkHalfGCDEmulated[EdBLS12377Fr](api, sBits, bits, signs)
+	case twistededwards.BLS12_381:
+		checkHalfGCDEmulated[JubjubFr](api, sBits, bits, signs)
+	case twistededwards.BLS12_381_BANDERSNATCH:
+		checkHalfGCDEmulated[BandersnatchFr](api, sBits, bits, signs)
+	case twistededwards.BW6_761:
+		checkHalfGCDEmulated[EdBW6761Fr](api, sBits, bits, signs)
+	case twistededwards.BW6_633:
+		checkHalfGCDEmulated[EdBW6633Fr](api, sBits, bits, signs)
+	case twistededwards.BLS24_315:
+		checkHalfGCDEmulated[EdBLS24315Fr](api, sBits, bits, signs)
+	case twistededwards.BLS24_317:
+		checkHalfGCDEmulated[EdBLS24317Fr](api, sBits, bits, signs)
+	default:
+		panic("unknown twisted edwards curve id")
+	}
+}
+
+func checkHalfGCDEmulated[T emulated.FieldParams](api frontend.API, sBits []frontend.Variable, bits [2][]frontend.Variable, signs [2]frontend.Variable) {
+	sapi, err := emulated.NewField[T](api)
+	if err != nil {
+		panic(err)
+	}
+	var sd [2]*emulated.Element[T]
+	for i := range sd {
+		api.AssertIsBoolean(signs[i])
+		abs := sapi.FromBits(bits[i]...)
+		sd[i] = sapi.Select(signs[i], sapi.Neg(abs), abs)
+	}
+	sEmu := sapi.FromBits(sBits...)
 
-func (fp BandersnatchFr) Modulus() *big.Int {
-	val := bandersnatch.GetEdwardsCurve().Order
-	return &val
+	// s1 + s * s2 == 0 mod r
+	lhs := sapi.MulNoReduce(sd[1], sEmu)
+	lhs = sapi.Add(lhs, sd[0])
+	sapi.AssertIsEqual(lhs, sapi.Zero())
+}
+
+// decompose takes the number of limbs n, the limb width w and a scalar s, and
+// outputs the n little-endian w-bit limbs of s. The scalar must fit in n*w
+// bits.
+func decompose(mod *big.Int, inputs, outputs []*big.Int) error {
+	if len(inputs) != 3 {
+		return errors.New("expecting three inputs")
+	}
+	if !inputs[0].IsUint64() || !inputs[1].IsUint64() || inputs[1].Uint64() == 0 || inputs[1].Uint64() > 64 {
+		return errors.New("decompose: invalid limb parameters")
+	}
+	nbLimbs, width := inputs[0].Uint64(), uint(inputs[1].Uint64())
+	if uint64(len(outputs)) != nbLimbs {
+		return fmt.Errorf("expecting %d outputs", nbLimbs)
+	}
+	s := inputs[2]
+	if s.Sign() < 0 || uint64(s.BitLen()) > nbLimbs*uint64(width) {
+		return errors.New("decompose: the scalar does not fit in the limbs")
+	}
+	mask := new(big.Int).Lsh(big.NewInt(1), width)
+	mask.Sub(mask, big.NewInt(1))
+	tmp := new(big.Int).Set(s)
+	for i := range outputs {
+		outputs[i].And(tmp, mask)
+		tmp.Rsh(tmp, width)
+	}
+	return nil
 }
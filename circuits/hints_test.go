@@ -0,0 +1,530 @@
+package circuits
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/bandersnatch"
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	tEd "github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/test"
+)
+
+// halfGCDZZ2BatchCircuit decomposes all its scalars with one
+// halfGCDZZ2Batch hint call and checks every decomposition in-circuit.
+type halfGCDZZ2BatchCircuit struct {
+	S []frontend.Variable
+}
+
+func (circuit *halfGCDZZ2BatchCircuit) Define(api frontend.API) error {
+	params := mustCurveParams(twistededwards.BLS12_381_BANDERSNATCH)
+	inputs := append([]frontend.Variable{params.Lambda}, circuit.S...)
+	res, err := api.NewHint(halfGCDZZ2Batch, zz2NbOutputs*len(circuit.S), inputs...)
+	if err != nil {
+		return err
+	}
+	n := params.Order.BitLen()/4 + 9
+	for i := range circuit.S {
+		out := res[zz2NbOutputs*i : zz2NbOutputs*(i+1)]
+		var bits [4][]frontend.Variable
+		var signs [4]frontend.Variable
+		for j := range bits {
+			bits[j] = api.ToBinary(out[zz2MagnitudesOffset+j], n)
+			signs[j] = out[zz2SignsOffset+j]
+		}
+		checkHalfGCDZZ2(api, api.ToBinary(circuit.S[i], params.Order.BitLen()), params.Lambda, bits, signs)
+	}
+	return nil
+}
+
+func TestHalfGCDZZ2Batch(t *testing.T) {
+	curve := bandersnatch.GetEdwardsCurve()
+	field := ecc.BLS12_381.ScalarField()
+	lambda := mustCurveParams(twistededwards.BLS12_381_BANDERSNATCH).Lambda
+
+	scalars := edgeScalars(&curve.Order)[:4]
+	for i := 0; i < 4; i++ {
+		s, _ := rand.Int(rand.Reader, &curve.Order)
+		scalars = append(scalars, s)
+	}
+
+	// the batch outputs are the ones of halfGCDZZ2Combined, in order
+	outputs := newBigInts(zz2NbOutputs * len(scalars))
+	if err := halfGCDZZ2Batch(field, append([]*big.Int{lambda}, scalars...), outputs); err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range scalars {
+		single := newBigInts(zz2NbOutputs)
+		if err := halfGCDZZ2Combined(field, []*big.Int{s, lambda}, single); err != nil {
+			t.Fatal(err)
+		}
+		for j := range single {
+			if outputs[zz2NbOutputs*i+j].Cmp(single[j]) != 0 {
+				t.Fatalf("scalar %d: batch output %d does not match the single hint", i, j)
+			}
+		}
+	}
+	if err := halfGCDZZ2Batch(field, []*big.Int{lambda, scalars[0]}, newBigInts(zz2NbOutputs-1)); err == nil {
+		t.Fatal("expected an error for a wrong number of outputs")
+	}
+
+	// all the decompositions of one hint call verify the in-circuit check
+	circuit := halfGCDZZ2BatchCircuit{S: make([]frontend.Variable, len(scalars))}
+	assignment := halfGCDZZ2BatchCircuit{S: make([]frontend.Variable, len(scalars))}
+	for i, s := range scalars {
+		assignment.S[i] = new(big.Int).Mod(s, &curve.Order)
+	}
+	if err := test.IsSolved(&circuit, &assignment, field); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHalfGCDZZ2Combined(t *testing.T) {
+	curve := bandersnatch.GetEdwardsCurve()
+	field := ecc.BLS12_381.ScalarField()
+	lambda := mustCurveParams(twistededwards.BLS12_381_BANDERSNATCH).Lambda
+
+	scalars := edgeScalars(&curve.Order)
+	for i := 0; i < 10; i++ {
+		s, _ := rand.Int(rand.Reader, &curve.Order)
+		scalars = append(scalars, s)
+	}
+	for _, s := range scalars {
+		outputs := newBigInts(zz2NbOutputs)
+		if err := halfGCDZZ2Combined(field, []*big.Int{s, lambda}, outputs); err != nil {
+			t.Fatal(err)
+		}
+
+		// u1 + λ*u2 + s*(v1 + λ*v2) == 0 mod r
+		var uv [4]big.Int
+		for j := range uv {
+			uv[j].Set(outputs[zz2MagnitudesOffset+j])
+			if outputs[zz2SignsOffset+j].Sign() != 0 {
+				uv[j].Neg(&uv[j])
+			}
+		}
+		var lhs, tmp big.Int
+		lhs.Mul(&uv[1], lambda).Add(&lhs, &uv[0])
+		tmp.Mul(&uv[3], lambda).Add(&tmp, &uv[2]).Mul(&tmp, s)
+		lhs.Add(&lhs, &tmp).Mod(&lhs, &curve.Order)
+		if lhs.Sign() != 0 {
+			t.Fatal("u1 + λ*u2 + s*(v1 + λ*v2) != 0 mod r")
+		}
+
+		if uv[2].Sign() == 0 && uv[3].Sign() == 0 {
+			t.Fatalf("(v1, v2) = (0, 0) for s = %s", s)
+		}
+	}
+
+	if err := halfGCDZZ2Combined(field, []*big.Int{lambda, lambda}, newBigInts(zz2NbOutputs-1)); err == nil {
+		t.Fatal("expected an error for a wrong number of outputs")
+	}
+}
+
+func TestGLVDecompose(t *testing.T) {
+	params := mustCurveParams(twistededwards.BLS12_381_BANDERSNATCH)
+	scalars := edgeScalars(params.Order)
+	for i := 0; i < 10; i++ {
+		s, _ := rand.Int(rand.Reader, params.Order)
+		scalars = append(scalars, s)
+	}
+	n := params.Order.BitLen()/2 + 1
+	for _, s := range scalars {
+		outputs := newBigInts(4)
+		if err := glvDecompose(params.Field, []*big.Int{s, params.Lambda}, outputs); err != nil {
+			t.Fatal(err)
+		}
+		if outputs[0].BitLen() > n || outputs[1].BitLen() > n {
+			t.Fatalf("decomposition of %s out of bounds", s)
+		}
+		// the constant-time lifting returns the same representative
+		UseConstantTimeHints(true)
+		ctOutputs := newBigInts(4)
+		err := glvDecompose(params.Field, []*big.Int{s, params.Lambda}, ctOutputs)
+		UseConstantTimeHints(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range outputs {
+			if outputs[i].Cmp(ctOutputs[i]) != 0 {
+				t.Fatalf("constant-time decomposition of %s differs", s)
+			}
+		}
+		var s1, s2 big.Int
+		s1.Set(outputs[0])
+		s2.Set(outputs[1])
+		if outputs[2].Sign() != 0 {
+			s1.Neg(&s1)
+		}
+		if outputs[3].Sign() != 0 {
+			s2.Neg(&s2)
+		}
+		// s1 + λ*s2 == s mod r
+		s2.Mul(&s2, params.Lambda).Add(&s2, &s1).Sub(&s2, s).Mod(&s2, params.Order)
+		if s2.Sign() != 0 {
+			t.Fatalf("s1 + λ*s2 != s mod r for s = %s", s)
+		}
+	}
+
+	if err := glvDecompose(ecc.BN254.ScalarField(), []*big.Int{big.NewInt(1), params.Lambda}, newBigInts(4)); err == nil {
+		t.Fatal("expected an error on a curve without endomorphism")
+	}
+}
+
+func TestJointDecompose(t *testing.T) {
+	for _, id := range curveIDs {
+		params := mustCurveParams(id)
+		scalars := edgeScalars(params.Order)
+		for i := 0; i < 4; i++ {
+			s, _ := rand.Int(rand.Reader, params.Order)
+			scalars = append(scalars, s)
+		}
+		n := jointDecomposeBits(params.Order)
+		for _, a := range scalars {
+			for _, b := range scalars {
+				outputs := newBigInts(6)
+				if err := jointDecompose(params.Field, []*big.Int{a, b, params.Order}, outputs); err != nil {
+					t.Fatalf("%s: %v", curveNames[id], err)
+				}
+				var k [3]big.Int
+				for i := range k {
+					if outputs[i].BitLen() > n {
+						t.Fatalf("%s: decomposition of (%s, %s) out of bounds", curveNames[id], a, b)
+					}
+					k[i].Set(outputs[i])
+					if outputs[3+i].Sign() != 0 {
+						k[i].Neg(&k[i])
+					}
+				}
+				if k[2].Sign() == 0 {
+					t.Fatalf("%s: v = 0 for (%s, %s)", curveNames[id], a, b)
+				}
+				// u1 + a*v == 0 and u2 + b*v == 0 mod r
+				for i, s := range []*big.Int{a, b} {
+					var tmp big.Int
+					tmp.Mul(s, &k[2]).Add(&tmp, &k[i]).Mod(&tmp, params.Order)
+					if tmp.Sign() != 0 {
+						t.Fatalf("%s: u%d + s*v != 0 mod r for (%s, %s)", curveNames[id], i+1, a, b)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestJointDecomposeGLV(t *testing.T) {
+	params := mustCurveParams(twistededwards.BLS12_381_BANDERSNATCH)
+	scalars := edgeScalars(params.Order)
+	for i := 0; i < 4; i++ {
+		s, _ := rand.Int(rand.Reader, params.Order)
+		scalars = append(scalars, s)
+	}
+	n := jointDecomposeGLVBits(params.Order)
+	for _, a := range scalars {
+		for _, b := range scalars {
+			outputs := newBigInts(12)
+			if err := jointDecomposeGLV(params.Field, []*big.Int{a, b, params.Lambda}, outputs); err != nil {
+				t.Fatal(err)
+			}
+			var k [6]big.Int
+			for i := range k {
+				if outputs[i].BitLen() > n {
+					t.Fatalf("decomposition of (%s, %s) out of bounds", a, b)
+				}
+				k[i].Set(outputs[i])
+				if outputs[6+i].Sign() != 0 {
+					k[i].Neg(&k[i])
+				}
+			}
+			// v = v1 + λ*v2 ≠ 0 mod r
+			var v big.Int
+			v.Mul(&k[5], params.Lambda).Add(&v, &k[4]).Mod(&v, params.Order)
+			if v.Sign() == 0 {
+				t.Fatalf("v1 + λ*v2 = 0 mod r for (%s, %s)", a, b)
+			}
+			// u1 + λ*u2 + a*v == 0 and u3 + λ*u4 + b*v == 0 mod r
+			for i, s := range []*big.Int{a, b} {
+				var tmp big.Int
+				tmp.Mul(&k[2*i+1], params.Lambda).Add(&tmp, &k[2*i])
+				tmp.Add(&tmp, new(big.Int).Mul(s, &v)).Mod(&tmp, params.Order)
+				if tmp.Sign() != 0 {
+					t.Fatalf("relation %d does not hold for (%s, %s)", i+1, a, b)
+				}
+			}
+		}
+	}
+
+	if err := jointDecomposeGLV(ecc.BN254.ScalarField(), []*big.Int{big.NewInt(1), big.NewInt(1), params.Lambda}, newBigInts(12)); err == nil {
+		t.Fatal("expected an error on a curve without endomorphism")
+	}
+}
+
+// msmScalars returns n scalars mixing the edge scalars and random ones.
+func msmScalars(order *big.Int, n int) []*big.Int {
+	edge := edgeScalars(order)
+	res := make([]*big.Int, n)
+	for i := range res {
+		if i%2 == 1 {
+			res[i] = edge[(i/2)%len(edge)]
+		} else {
+			res[i], _ = rand.Int(rand.Reader, order)
+		}
+	}
+	return res
+}
+
+// signedOutputs returns the values laid out as their magnitudes followed by
+// their sign bits.
+func signedOutputs(outputs []*big.Int) []*big.Int {
+	k := make([]*big.Int, len(outputs)/2)
+	for i := range k {
+		k[i] = new(big.Int).Set(outputs[i])
+		if outputs[len(k)+i].Sign() != 0 {
+			k[i].Neg(k[i])
+		}
+	}
+	return k
+}
+
+func TestMSMDecompose(t *testing.T) {
+	for _, id := range curveIDs {
+		params := mustCurveParams(id)
+		for nbScalars := 1; nbScalars <= msmMaxPoints(params.Order); nbScalars++ {
+			scalars := msmScalars(params.Order, nbScalars)
+			outputs := newBigInts(2 * (nbScalars + 1))
+			if err := msmDecompose(params.Field, append(scalars, params.Order), outputs); err != nil {
+				t.Fatalf("%s: %v", curveNames[id], err)
+			}
+			n := multiDecomposeBits(params.Order, nbScalars)
+			k := signedOutputs(outputs)
+			for i := range k {
+				if k[i].BitLen() > n {
+					t.Fatalf("%s: decomposition of %d scalars out of bounds", curveNames[id], nbScalars)
+				}
+			}
+			v := k[nbScalars]
+			if v.Sign() == 0 {
+				t.Fatalf("%s: v = 0 for %d scalars", curveNames[id], nbScalars)
+			}
+			// u_i + s_i*v == 0 mod r
+			for i, s := range scalars {
+				var tmp big.Int
+				tmp.Mul(s, v).Add(&tmp, k[i]).Mod(&tmp, params.Order)
+				if tmp.Sign() != 0 {
+					t.Fatalf("%s: u_%d + s_%d*v != 0 mod r", curveNames[id], i+1, i+1)
+				}
+			}
+		}
+	}
+}
+
+func TestMSMDecomposeGLV(t *testing.T) {
+	params := mustCurveParams(twistededwards.BLS12_381_BANDERSNATCH)
+	for nbScalars := 1; nbScalars <= msmMaxPointsGLV(params.Order); nbScalars++ {
+		scalars := msmScalars(params.Order, nbScalars)
+		outputs := newBigInts(4 * (nbScalars + 1))
+		if err := msmDecomposeGLV(params.Field, append(scalars, params.Lambda), outputs); err != nil {
+			t.Fatal(err)
+		}
+		n := multiDecomposeGLVBits(params.Order, nbScalars)
+		k := signedOutputs(outputs)
+		for i := range k {
+			if k[i].BitLen() > n {
+				t.Fatalf("decomposition of %d scalars out of bounds", nbScalars)
+			}
+		}
+		// v = v1 + λ*v2 ≠ 0 mod r
+		var v big.Int
+		v.Mul(k[2*nbScalars+1], params.Lambda).Add(&v, k[2*nbScalars]).Mod(&v, params.Order)
+		if v.Sign() == 0 {
+			t.Fatalf("v1 + λ*v2 = 0 mod r for %d scalars", nbScalars)
+		}
+		// u_i,1 + λ*u_i,2 + s_i*v == 0 mod r
+		for i, s := range scalars {
+			var tmp big.Int
+			tmp.Mul(k[2*i+1], params.Lambda).Add(&tmp, k[2*i])
+			tmp.Add(&tmp, new(big.Int).Mul(s, &v)).Mod(&tmp, params.Order)
+			if tmp.Sign() != 0 {
+				t.Fatalf("relation %d does not hold for %d scalars", i+1, nbScalars)
+			}
+		}
+	}
+
+	if err := msmDecomposeGLV(params.Field, []*big.Int{big.NewInt(1), params.Lambda}, newBigInts(7)); err == nil {
+		t.Fatal("expected an error for a wrong number of outputs")
+	}
+}
+
+func TestHalfGCD(t *testing.T) {
+	for _, id := range curveIDs {
+		order := mustCurveParams(id).Order
+		scalars := edgeScalars(order)
+		for i := 0; i < 10; i++ {
+			s, _ := rand.Int(rand.Reader, order)
+			scalars = append(scalars, s)
+		}
+		n := order.BitLen()/2 + 1
+		for _, s := range scalars {
+			outputs := newBigInts(4)
+			if err := halfGCD(nil, []*big.Int{s, order}, outputs); err != nil {
+				t.Fatal(err)
+			}
+			var s1, s2 big.Int
+			s1.Set(outputs[0])
+			s2.Set(outputs[1])
+			if outputs[0].BitLen() > n || outputs[1].BitLen() > n {
+				t.Fatalf("%s: decomposition of %s out of bounds", curveNames[id], s)
+			}
+			if s2.Sign() == 0 {
+				t.Fatalf("%s: s2 = 0 for s = %s", curveNames[id], s)
+			}
+			if outputs[2].Sign() != 0 {
+				s1.Neg(&s1)
+			}
+			if outputs[3].Sign() != 0 {
+				s2.Neg(&s2)
+			}
+			// s1 + s*s2 == 0 mod r
+			s2.Mul(&s2, s).Add(&s2, &s1).Mod(&s2, order)
+			if s2.Sign() != 0 {
+				t.Fatalf("%s: s1 + s*s2 != 0 mod r for s = %s", curveNames[id], s)
+			}
+		}
+	}
+}
+
+// edgeScalars returns 0, 1, r-1, r and 2^253-1.
+func edgeScalars(order *big.Int) []*big.Int {
+	one := big.NewInt(1)
+	return []*big.Int{
+		big.NewInt(0),
+		one,
+		new(big.Int).Sub(order, one),
+		new(big.Int).Set(order),
+		new(big.Int).Sub(new(big.Int).Lsh(one, 253), one),
+	}
+}
+
+func newBigInts(n int) []*big.Int {
+	res := make([]*big.Int, n)
+	for i := range res {
+		res[i] = new(big.Int)
+	}
+	return res
+}
+
+func TestConstantTimeHints(t *testing.T) {
+	const nbScalars = 5
+	curve := bandersnatch.GetEdwardsCurve()
+	field := ecc.BLS12_381.ScalarField()
+	lambda := mustCurveParams(twistededwards.BLS12_381_BANDERSNATCH).Lambda
+
+	scalars := make([]*big.Int, nbScalars)
+	expected := make([][]*big.Int, nbScalars)
+	for i := range scalars {
+		scalars[i], _ = rand.Int(rand.Reader, &curve.Order)
+		expected[i] = newBigInts(zz2NbOutputs)
+		if err := halfGCDZZ2Combined(field, []*big.Int{scalars[i], lambda}, expected[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	UseConstantTimeHints(true)
+	defer UseConstantTimeHints(false)
+
+	for i, s := range scalars {
+		outputs := newBigInts(zz2NbOutputs)
+		if err := halfGCDZZ2Combined(field, []*big.Int{s, lambda}, outputs); err != nil {
+			t.Fatal(err)
+		}
+		for j := range outputs {
+			if outputs[j].Cmp(expected[i][j]) != 0 {
+				t.Fatalf("scalar %d: constant-time output %d does not match the variable-time one", i, j)
+			}
+		}
+	}
+}
+
+func TestScalarMulHint(t *testing.T) {
+	for _, id := range curveIDs {
+		params, err := tEd.GetCurveParams(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		field, _ := tEd.GetSnarkField(id)
+		mul := func(x, y, s *big.Int) (*big.Int, *big.Int) {
+			outputs := newBigInts(2)
+			if err := scalarMulHint(field, []*big.Int{x, y, s, big.NewInt(int64(id))}, outputs); err != nil {
+				t.Fatal(err)
+			}
+			return outputs[0], outputs[1]
+		}
+
+		// [s1*s2]G == [s1]([s2]G)
+		s1, _ := rand.Int(rand.Reader, params.Order)
+		s2, _ := rand.Int(rand.Reader, params.Order)
+		s := new(big.Int).Mul(s1, s2)
+		x, y := mul(params.Base[0], params.Base[1], s)
+		x2, y2 := mul(params.Base[0], params.Base[1], s2)
+		x2, y2 = mul(x2, y2, s1)
+		if x.Cmp(x2) != 0 || y.Cmp(y2) != 0 {
+			t.Fatalf("curve %d: [s1*s2]G != [s1]([s2]G)", id)
+		}
+		// [r]G == (0, 1)
+		x, y = mul(params.Base[0], params.Base[1], params.Order)
+		if x.Sign() != 0 || y.Cmp(big.NewInt(1)) != 0 {
+			t.Fatalf("curve %d: [r]G is not the identity", id)
+		}
+	}
+
+	// unknown curve and mismatching field
+	field := ecc.BLS12_381.ScalarField()
+	one := big.NewInt(1)
+	if err := scalarMulHint(field, []*big.Int{one, one, one, big.NewInt(255)}, newBigInts(2)); err == nil {
+		t.Fatal("expected an error for an unknown curve")
+	}
+	if err := scalarMulHint(field, []*big.Int{one, one, one, big.NewInt(int64(twistededwards.BN254))}, newBigInts(2)); err == nil {
+		t.Fatal("expected an error for a curve over another field")
+	}
+}
+
+func TestDecompose(t *testing.T) {
+	s, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 253))
+	for _, tc := range []struct{ nbLimbs, width int }{{4, 64}, {5, 51}, {6, 43}, {253, 1}} {
+		outputs := newBigInts(tc.nbLimbs)
+		if err := decompose(nil, []*big.Int{big.NewInt(int64(tc.nbLimbs)), big.NewInt(int64(tc.width)), s}, outputs); err != nil {
+			t.Fatal(err)
+		}
+		res := new(big.Int)
+		for i := tc.nbLimbs - 1; i >= 0; i-- {
+			if outputs[i].BitLen() > tc.width {
+				t.Fatalf("%d×%d bits: limb %d is too wide", tc.nbLimbs, tc.width, i)
+			}
+			res.Lsh(res, uint(tc.width)).Add(res, outputs[i])
+		}
+		if res.Cmp(s) != 0 {
+			t.Fatalf("%d×%d bits: the limbs do not recompose to the scalar", tc.nbLimbs, tc.width)
+		}
+	}
+
+	four, w := big.NewInt(4), big.NewInt(64)
+	for _, tc := range []struct {
+		name    string
+		inputs  []*big.Int
+		outputs int
+	}{
+		{"missing limb parameters", []*big.Int{s}, 4},
+		{"too few outputs", []*big.Int{four, w, s}, 3},
+		{"too many outputs", []*big.Int{four, w, s}, 5},
+		{"limbs wider than 64 bits", []*big.Int{big.NewInt(2), big.NewInt(128), s}, 2},
+		{"scalar too large", []*big.Int{four, w, new(big.Int).Lsh(big.NewInt(1), 256)}, 4},
+		{"negative scalar", []*big.Int{four, w, big.NewInt(-1)}, 4},
+	} {
+		if err := decompose(nil, tc.inputs, newBigInts(tc.outputs)); err == nil {
+			t.Fatalf("%s: expected an error", tc.name)
+		}
+	}
+}
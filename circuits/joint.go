@@ -0,0 +1,210 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	tEd "github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+)
+
+// JointScalarMul computes the double-scalar multiplication [a]p + [b]q on the
+// twisted Edwards curve id, e.g. for a signature verification, as:
+//
+//	[a0]p + [b0]q + r' with [u1]P + [u2]Q + [v]r' = (0,1)
+//
+// where P = [h]p, Q = [h]q, a = a0 + h * a', b = b0 + h * b' with
+// 0 ≤ a0, b0 < h the cofactor, u1 + a' * v == 0 mod r, u2 + b' * v == 0 mod r,
+// v ≠ 0 and |u1|,|u2|,|v| < 1.17 * r^(2/3). The relations mod r are checked
+// with non-native arithmetic.
+//
+// The hinted r' is checked with one loop of ≈ 2/3 * log2(r) doublings and
+// additions from an 8-entry table, instead of two scalar multiplications of
+// ≈ 1/2 * log2(r) doublings and additions each.
+//
+// As for ScalarMulFakeGLV, p and q can be any points of the curve and a and b
+// any scalars. On Bandersnatch, whose twisted Edwards model is incomplete, a
+// partial sum at infinity is rejected; this cannot happen for points in the
+// prime subgroup.
+func JointScalarMul(api frontend.API, p, q *tEd.Point, a, b frontend.Variable, id twistededwards.ID) *tEd.Point {
+	// get edwards curve curve
+	curve, err := tEd.NewEdCurve(api, id)
+	if err != nil {
+		return nil
+	}
+	params, err := getCurveParams(id)
+	if err != nil {
+		return nil
+	}
+
+	lowP, pc, aBits := splitScalar(api, curve, p, a, params.Cofactor)
+	lowQ, qc, bBits := splitScalar(api, curve, q, b, params.Cofactor)
+	aHi := api.FromBinary(aBits...)
+	bHi := api.FromBinary(bBits...)
+
+	// the hint allows to decompose the scalars a' and b' into u1, u2 and v
+	// such that u1 + a' * v == 0 mod Order and u2 + b' * v == 0 mod Order.
+	s, err := api.NewHint(jointDecompose, 6, aHi, bHi, params.Order)
+	if err != nil {
+		// err is non-nil only for invalid number of inputs
+		panic(err)
+	}
+	u1, u2, v, isNeg1, isNeg2, isNeg3 := s[0], s[1], s[2], s[3], s[4], s[5]
+
+	// |u1|,|u2|,|v| < 1.17 * r^(2/3)
+	n := jointDecomposeBits(params.Order)
+	b1 := api.ToBinary(u1, n)
+	b2 := api.ToBinary(u2, n)
+	b3 := api.ToBinary(v, n)
+
+	// v ≠ 0 mod r, otherwise u1 = u2 = v = 0 verifies the relation for any
+	// claimed result.
+	api.AssertIsDifferent(v, 0)
+
+	// check both relations on the bits and signs used below, with the same v
+	checkHalfGCD(api, id, aBits, [2][]frontend.Variable{b1, b3}, [2]frontend.Variable{isNeg1, isNeg3})
+	checkHalfGCD(api, id, bBits, [2][]frontend.Variable{b2, b3}, [2]frontend.Variable{isNeg2, isNeg3})
+
+	// r' = [a']P + [b']Q = [h](w1 + w2) is in the prime subgroup
+	w1 := scalarMulCofactorInverse(api, &pc, aHi, params)
+	w2 := scalarMulCofactorInverse(api, &qc, bHi, params)
+	r := clearCofactor(api, curve, curve.Add(w1, w2), params)
+
+	// with P, Q and r' in the prime subgroup, r' = [a']P + [b']Q is
+	// equivalent to [u1]P + [u2]Q + [v]r' = (0,1)
+	var t [8]tEd.Point
+	t[0] = tEd.Point{X: 0, Y: 1}
+	t[1] = tEd.Point{X: api.Select(isNeg1, api.Neg(pc.X), pc.X), Y: pc.Y}
+	t[2] = tEd.Point{X: api.Select(isNeg2, api.Neg(qc.X), qc.X), Y: qc.Y}
+	t[3] = curve.Add(t[1], t[2])
+	t[4] = tEd.Point{X: api.Select(isNeg3, api.Neg(r.X), r.X), Y: r.Y}
+	t[5] = curve.Add(t[1], t[4])
+	t[6] = curve.Add(t[2], t[4])
+	t[7] = curve.Add(t[3], t[4])
+
+	tblX := logderivlookup.New(api)
+	tblY := logderivlookup.New(api)
+	for i := range t {
+		tblX.Insert(t[i].X)
+		tblY.Insert(t[i].Y)
+	}
+	inds := make([]frontend.Variable, n)
+	for i := range inds {
+		inds[i] = api.Add(b1[i], api.Mul(b2[i], 2), api.Mul(b3[i], 4))
+	}
+	xs := tblX.Lookup(inds...)
+	ys := tblY.Lookup(inds...)
+	res := tEd.Point{X: xs[n-1], Y: ys[n-1]}
+	for i := n - 2; i >= 0; i-- {
+		res = curve.Double(res)
+		res = curve.Add(res, tEd.Point{X: xs[i], Y: ys[i]})
+	}
+
+	api.AssertIsEqual(res.X, 0)
+	api.AssertIsEqual(res.Y, 1)
+
+	res = curve.Add(curve.Add(lowP, lowQ), r)
+	return &res
+}
+
+// JointScalarMulGLV computes the double-scalar multiplication [a]p + [b]q on
+// the Bandersnatch curve in twisted Edwards form as:
+//
+//	[a0]p + [b0]q + r' with
+//	[u1]P + [u2]φ(P) + [u3]Q + [u4]φ(Q) + [v1]r' + [v2]φ(r') = (0,1)
+//
+// where P = [h]p, Q = [h]q, a = a0 + h * a', b = b0 + h * b' with
+// 0 ≤ a0, b0 < h the cofactor, u1 + λ*u2 + a'*(v1 + λ*v2) == 0 mod r,
+// u3 + λ*u4 + b'*(v1 + λ*v2) == 0 mod r, (v1, v2) ≠ (0, 0) and all the
+// values smaller than 1.46 * r^(1/3). The relations mod r are checked with
+// non-native arithmetic.
+//
+// It is JointScalarMul with the √−2 endomorphism, which halves the length of
+// the loop at the cost of a 64-entry table. As for JointScalarMul, p and q can
+// be any points of the curve and a and b any scalars.
+func JointScalarMulGLV(api frontend.API, p, q *tEd.Point, a, b frontend.Variable) *tEd.Point {
+	// get edwards curve curve
+	curve, err := tEd.NewEdCurve(api, twistededwards.BLS12_381_BANDERSNATCH)
+	if err != nil {
+		return nil
+	}
+	params := mustCurveParams(twistededwards.BLS12_381_BANDERSNATCH)
+
+	lowP, pc, aBits := splitScalar(api, curve, p, a, params.Cofactor)
+	lowQ, qc, bBits := splitScalar(api, curve, q, b, params.Cofactor)
+	aHi := api.FromBinary(aBits...)
+	bHi := api.FromBinary(bBits...)
+
+	// the hint allows to decompose the scalars a' and b' into u1, u2, u3, u4,
+	// v1 and v2 such that u1 + λ*u2 + a' * (v1 + λ*v2) == 0 mod Order and
+	// u3 + λ*u4 + b' * (v1 + λ*v2) == 0 mod Order.
+	s, err := api.NewHint(jointDecomposeGLV, 12, aHi, bHi, params.Lambda)
+	if err != nil {
+		// err is non-nil only for invalid number of inputs
+		panic(err)
+	}
+
+	// |u1|,|u2|,|u3|,|u4|,|v1|,|v2| < 1.46 * r^(1/3)
+	n := jointDecomposeGLVBits(params.Order)
+	var bits [6][]frontend.Variable
+	for i := range bits {
+		bits[i] = api.ToBinary(s[i], n)
+	}
+	signs := s[6:]
+
+	// check both relations on the bits and signs used below, with the same
+	// v1 + λ*v2, which is also checked to be non-zero
+	checkHalfGCDZZ2(api, aBits, params.Lambda,
+		[4][]frontend.Variable{bits[0], bits[1], bits[4], bits[5]},
+		[4]frontend.Variable{signs[0], signs[1], signs[4], signs[5]},
+	)
+	checkHalfGCDZZ2(api, bBits, params.Lambda,
+		[4][]frontend.Variable{bits[2], bits[3], bits[4], bits[5]},
+		[4]frontend.Variable{signs[2], signs[3], signs[4], signs[5]},
+	)
+
+	// r' = [a']P + [b']Q = [h](w1 + w2) is in the prime subgroup
+	w1 := scalarMulCofactorInverse(api, &pc, aHi, params)
+	w2 := scalarMulCofactorInverse(api, &qc, bHi, params)
+	r := clearCofactor(api, curve, curve.Add(w1, w2), params)
+
+	// with P, Q and r' in the prime subgroup, r' = [a']P + [b']Q is
+	// equivalent to the relation above. The table holds the sums of the
+	// subsets of the six signed points, the i-th point at bit i of the index.
+	points := [6]tEd.Point{pc, *phi(api, &pc), qc, *phi(api, &qc), r, *phi(api, &r)}
+	var t [64]tEd.Point
+	t[0] = tEd.Point{X: 0, Y: 1}
+	for i := range points {
+		pt := tEd.Point{X: api.Select(signs[i], api.Neg(points[i].X), points[i].X), Y: points[i].Y}
+		t[1<<i] = pt
+		for j := 1; j < 1<<i; j++ {
+			t[j+1<<i] = curve.Add(t[j], pt)
+		}
+	}
+
+	tblX := logderivlookup.New(api)
+	tblY := logderivlookup.New(api)
+	for i := range t {
+		tblX.Insert(t[i].X)
+		tblY.Insert(t[i].Y)
+	}
+	inds := make([]frontend.Variable, n)
+	for i := range inds {
+		inds[i] = 0
+		for k := len(bits) - 1; k >= 0; k-- {
+			inds[i] = api.Add(api.Mul(inds[i], 2), bits[k][i])
+		}
+	}
+	xs := tblX.Lookup(inds...)
+	ys := tblY.Lookup(inds...)
+	res := tEd.Point{X: xs[n-1], Y: ys[n-1]}
+	for i := n - 2; i >= 0; i-- {
+		res = curve.Double(res)
+		res = curve.Add(res, tEd.Point{X: xs[i], Y: ys[i]})
+	}
+
+	api.AssertIsEqual(res.X, 0)
+	api.AssertIsEqual(res.Y, 1)
+
+	res = curve.Add(curve.Add(lowP, lowQ), r)
+	return &res
+}
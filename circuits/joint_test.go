@@ -0,0 +1,264 @@
+package circuits
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/profile"
+	tEd "github.com/consensys/gnark/std/algebra/native/twistededwards"
+)
+
+type jointScalarMul struct {
+	curveID twistededwards.ID
+	glv     bool
+	P, Q    tEd.Point
+	A, B    frontend.Variable
+	R       tEd.Point
+}
+
+func (circuit *jointScalarMul) Define(api frontend.API) error {
+	var res *tEd.Point
+	if circuit.glv {
+		res = JointScalarMulGLV(api, &circuit.P, &circuit.Q, circuit.A, circuit.B)
+	} else {
+		res = JointScalarMul(api, &circuit.P, &circuit.Q, circuit.A, circuit.B, circuit.curveID)
+	}
+	api.AssertIsEqual(res.X, circuit.R.X)
+	api.AssertIsEqual(res.Y, circuit.R.Y)
+
+	return nil
+}
+
+// twoScalarMuls computes [a]p + [b]q with two ScalarMulFakeGLV.
+type twoScalarMuls struct {
+	curveID twistededwards.ID
+	P, Q    tEd.Point
+	A, B    frontend.Variable
+	R       tEd.Point
+}
+
+func (circuit *twoScalarMuls) Define(api frontend.API) error {
+	curve, err := tEd.NewEdCurve(api, circuit.curveID)
+	if err != nil {
+		return err
+	}
+	res := curve.Add(
+		*ScalarMulFakeGLV(api, &circuit.P, circuit.A, circuit.curveID),
+		*ScalarMulFakeGLV(api, &circuit.Q, circuit.B, circuit.curveID),
+	)
+	api.AssertIsEqual(res.X, circuit.R.X)
+	api.AssertIsEqual(res.Y, circuit.R.Y)
+
+	return nil
+}
+
+func jointAssignment(p, q [2]*big.Int, a, b *big.Int, r [2]*big.Int) *jointScalarMul {
+	return &jointScalarMul{
+		P: tEd.Point{X: p[0], Y: p[1]},
+		Q: tEd.Point{X: q[0], Y: q[1]},
+		A: a,
+		B: b,
+		R: tEd.Point{X: r[0], Y: r[1]},
+	}
+}
+
+func TestJointScalarMul(t *testing.T) {
+	type variant struct {
+		name string
+		id   twistededwards.ID
+		glv  bool
+	}
+	var variants []variant
+	for _, id := range curveIDs {
+		variants = append(variants, variant{curveNames[id], id, false})
+	}
+	variants = append(variants, variant{"GLV", twistededwards.BLS12_381_BANDERSNATCH, true})
+	for _, v := range variants {
+		v := v
+		t.Run(v.name, func(t *testing.T) {
+			t.Parallel()
+			id := v.id
+			params := mustCurveParams(id)
+			ccs, err := frontend.Compile(params.Field, scs.NewBuilder, &jointScalarMul{curveID: id, glv: v.glv})
+			if err != nil {
+				t.Fatal(err)
+			}
+			points := curvePoints(id, 2)
+			if id == twistededwards.BLS12_381_BANDERSNATCH {
+				// on Bandersnatch [a]p + [b]q may be at infinity for p, q
+				// outside the prime subgroup, use [h]p and [h]q instead
+				for i := range points {
+					if err := scalarMulNative(id, points[i][0], points[i][1], params.Cofactor, points[i][0], points[i][1]); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			torsion := smallOrderPoint(id)
+			identity := [2]*big.Int{big.NewInt(0), big.NewInt(1)}
+			a, _ := rand.Int(rand.Reader, params.Order)
+			b, _ := rand.Int(rand.Reader, params.Order)
+
+			type testCase struct {
+				name string
+				p, q [2]*big.Int
+				a, b *big.Int
+			}
+			cases := []testCase{
+				{"random", points[0], points[1], a, b},
+				{"zero scalars", points[0], points[1], big.NewInt(0), big.NewInt(0)},
+				{"zero scalar", points[0], points[1], a, big.NewInt(0)},
+				{"edge scalars", points[0], points[1], new(big.Int).Sub(params.Order, big.NewInt(1)), new(big.Int).Set(params.Order)},
+				{"same scalar", points[0], points[1], a, a},
+				{"same point", points[0], points[0], a, b},
+				{"opposite scalars", points[0], points[0], a, new(big.Int).Sub(params.Order, a)},
+				{"identity", identity, points[1], a, b},
+			}
+			if id != twistededwards.BLS12_381_BANDERSNATCH {
+				cases = append(cases, testCase{"small order", torsion, addNative(id, points[1], torsion), a, b})
+			}
+			for _, tc := range cases {
+				r := multiScalarMulNative(t, id, [][2]*big.Int{tc.p, tc.q}, []*big.Int{tc.a, tc.b})
+				for _, expected := range []struct {
+					r     [2]*big.Int
+					valid bool
+				}{
+					{r, true},
+					{addNative(id, r, torsion), false},
+				} {
+					w, err := frontend.NewWitness(jointAssignment(tc.p, tc.q, tc.a, tc.b, expected.r), params.Field)
+					if err != nil {
+						t.Fatal(err)
+					}
+					err = ccs.IsSolved(w)
+					if expected.valid && err != nil {
+						t.Fatalf("%s: %v", tc.name, err)
+					}
+					if !expected.valid && err == nil {
+						t.Fatalf("%s: a wrong result was accepted", tc.name)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestJointScalarMulMaliciousHints checks that a prover replacing the hints
+// cannot make JointScalarMul or JointScalarMulGLV accept a wrong result.
+func TestJointScalarMulMaliciousHints(t *testing.T) {
+	for _, v := range []struct {
+		name      string
+		id        twistededwards.ID
+		glv       bool
+		decompose solver.Hint
+	}{
+		{curveNames[twistededwards.BLS12_381], twistededwards.BLS12_381, false, jointDecompose},
+		{"GLV", twistededwards.BLS12_381_BANDERSNATCH, true, jointDecomposeGLV},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			id := v.id
+			params := mustCurveParams(id)
+			points := curvePoints(id, 2)
+			for i := range points {
+				if err := scalarMulNative(id, points[i][0], points[i][1], params.Cofactor, points[i][0], points[i][1]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			a, _ := rand.Int(rand.Reader, params.Order)
+			b, _ := rand.Int(rand.Reader, params.Order)
+			r := multiScalarMulNative(t, id, points, []*big.Int{a, b})
+			// [a]p + [b]q + [h]p is wrong but in the same coset
+			hp := [2]*big.Int{new(big.Int), new(big.Int)}
+			if err := scalarMulNative(id, points[0][0], points[0][1], params.Cofactor, hp[0], hp[1]); err != nil {
+				t.Fatal(err)
+			}
+			wrong := addNative(id, r, hp)
+
+			zero := func(_ *big.Int, _, outputs []*big.Int) error {
+				for i := range outputs {
+					outputs[i].SetUint64(0)
+				}
+				return nil
+			}
+			// the decomposition of (a'+1, b'), matching the wrong result
+			shifted := func(mod *big.Int, inputs, outputs []*big.Int) error {
+				in := []*big.Int{new(big.Int).Add(inputs[0], big.NewInt(1)), inputs[1], inputs[2]}
+				return v.decompose(mod, in, outputs)
+			}
+			// the hinted point [a'+1]P instead of [a']P
+			shiftedMul := func(field *big.Int, inputs, outputs []*big.Int) error {
+				in := append([]*big.Int{}, inputs...)
+				if inputs[2].Cmp(new(big.Int).Div(a, params.Cofactor)) == 0 {
+					in[2] = new(big.Int).Add(inputs[2], big.NewInt(1))
+				}
+				return scalarMulHint(field, in, outputs)
+			}
+
+			ccs, err := frontend.Compile(params.Field, scs.NewBuilder, &jointScalarMul{curveID: id, glv: v.glv})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, tc := range []struct {
+				name  string
+				r     [2]*big.Int
+				opts  []solver.Option
+				valid bool
+			}{
+				{"honest", r, nil, true},
+				{"zero decomposition", wrong, []solver.Option{solver.OverrideHint(solver.GetHintID(v.decompose), zero)}, false},
+				{"shifted decomposition", wrong, []solver.Option{
+					solver.OverrideHint(solver.GetHintID(v.decompose), shifted),
+					solver.OverrideHint(solver.GetHintID(scalarMulHint), shiftedMul),
+				}, false},
+				{"shifted result", wrong, []solver.Option{solver.OverrideHint(solver.GetHintID(scalarMulHint), shiftedMul)}, false},
+			} {
+				w, err := frontend.NewWitness(jointAssignment(points[0], points[1], a, b, tc.r), params.Field)
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = ccs.IsSolved(w, tc.opts...)
+				if tc.valid && err != nil {
+					t.Fatalf("%s: %v", tc.name, err)
+				}
+				if !tc.valid && err == nil {
+					t.Fatalf("%s: the malicious hints were accepted", tc.name)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkJointScalarMul prints the number of constraints of [a]p + [b]q on
+// Jubjub and Bandersnatch, with JointScalarMul and with two ScalarMulFakeGLV.
+func BenchmarkJointScalarMul(b *testing.B) {
+	for _, id := range []twistededwards.ID{twistededwards.BLS12_381, twistededwards.BLS12_381_BANDERSNATCH} {
+		for _, c := range []struct {
+			name    string
+			circuit frontend.Circuit
+		}{
+			{"JointScalarMul", &jointScalarMul{curveID: id}},
+			{"JointScalarMulGLV", &jointScalarMul{curveID: id, glv: true}},
+			{"2 × 2D hinted GLV", &twoScalarMuls{curveID: id}},
+		} {
+			if c.name == "JointScalarMulGLV" && id != twistededwards.BLS12_381_BANDERSNATCH {
+				continue
+			}
+			p := profile.Start()
+			_, _ = frontend.Compile(ecc.BLS12_381.ScalarField(), scs.NewBuilder, c.circuit)
+			p.Stop()
+			fmt.Printf("%s %s (scs): %d\n", curveNames[id], c.name, p.NbConstraints())
+
+			p = profile.Start()
+			_, _ = frontend.Compile(ecc.BLS12_381.ScalarField(), r1cs.NewBuilder, c.circuit)
+			p.Stop()
+			fmt.Printf("%s %s (r1cs): %d\n", curveNames[id], c.name, p.NbConstraints())
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package circuits
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	tEd "github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+)
+
+// Window widths of the multi-scalar multiplications, in bits of each scalar
+// component.
+const (
+	msmWindow    = 4
+	msmWindowGLV = 2 // on both components of the Z[√−2] decomposition
+)
+
+// Largest numbers of points of one relation of the multi-scalar
+// multiplications. The LLL reductions of msmDecompose and msmDecomposeGLV,
+// in exact rational arithmetic, take about 0.4s and 0.7s at these sizes and
+// get much slower beyond, for a saving of a few percent per point.
+const (
+	msmMaxChunk    = 8
+	msmMaxChunkGLV = 4
+)
+
+// MultiScalarMul computes the multi-scalar multiplication Σ [s_i]p_i on the
+// twisted Edwards curve id as:
+//
+//	Σ [s0_i]p_i + r' with Σ [u_i]P_i + [v]r' = (0,1)
+//
+// where P_i = [h]p_i, s_i = s0_i + h * s'_i with 0 ≤ s0_i < h the cofactor,
+// u_i + s'_i * v == 0 mod r for all i, v ≠ 0 and |u_i|,|v| < 2^n with
+// n = multiDecomposeBits(r, N) ≈ N/(N+1) * log2(r). It is JointScalarMul for
+// N points: the N relations mod r share v and are checked with non-native
+// arithmetic.
+//
+// The hinted r' is checked with one double-and-add loop of n doublings, whose
+// 4-bit windows index one logderivlookup table holding the multiples
+// [0..15] of the signed P_i and r'. The doublings are shared between the
+// points, but n grows towards log2(r) with N and each point keeps its own
+// non-native check, so that the saving over N independent fake GLV scalar
+// multiplications levels off as N grows. The points are split into chunks of
+// at most msmMaxPoints(r) points, for which |v| < r ensures v ≠ 0 mod r.
+//
+// As for ScalarMulFakeGLV, the points can be any points of the curve and the
+// scalars any scalars. On Bandersnatch, whose twisted Edwards model is
+// incomplete, a partial sum at infinity is rejected; this cannot happen for
+// points in the prime subgroup.
+func MultiScalarMul(api frontend.API, points []*tEd.Point, scalars []frontend.Variable, id twistededwards.ID) *tEd.Point {
+	if len(points) != len(scalars) {
+		panic("MultiScalarMul: different numbers of points and scalars")
+	}
+	// get edwards curve curve
+	curve, err := tEd.NewEdCurve(api, id)
+	if err != nil {
+		return nil
+	}
+	params, err := getCurveParams(id)
+	if err != nil {
+		return nil
+	}
+	if len(points) == 0 {
+		return &tEd.Point{X: 0, Y: 1}
+	}
+	if max := msmMaxPoints(params.Order); len(points) > max {
+		return msmChunks(curve, points, scalars, max, func(points []*tEd.Point, scalars []frontend.Variable) *tEd.Point {
+			return MultiScalarMul(api, points, scalars, id)
+		})
+	}
+	nbPoints := len(points)
+
+	low, pcs, sBits, r := msmSplit(api, curve, points, scalars, params)
+
+	// the hint allows to decompose the scalars s'_i into u_i and v such that
+	// u_i + s'_i * v == 0 mod Order for all i.
+	inputs := make([]frontend.Variable, nbPoints+1)
+	for i := range sBits {
+		inputs[i] = api.FromBinary(sBits[i]...)
+	}
+	inputs[nbPoints] = params.Order
+	s, err := api.NewHint(msmDecompose, 2*(nbPoints+1), inputs...)
+	if err != nil {
+		// err is non-nil only for invalid number of inputs
+		panic(err)
+	}
+	signs := s[nbPoints+1:]
+
+	// |u_i|,|v| < 2^n
+	n := multiDecomposeBits(params.Order, nbPoints)
+	bits := make([][]frontend.Variable, nbPoints+1)
+	for i := range bits {
+		bits[i] = api.ToBinary(s[i], n)
+	}
+
+	// v ≠ 0 mod r, otherwise u_i = v = 0 verifies the relations for any
+	// claimed result.
+	api.AssertIsDifferent(s[nbPoints], 0)
+
+	// check all the relations on the bits and signs used below, with the
+	// same v
+	for i := range sBits {
+		checkHalfGCD(api, id, sBits[i], [2][]frontend.Variable{bits[i], bits[nbPoints]}, [2]frontend.Variable{signs[i], signs[nbPoints]})
+	}
+
+	// with the P_i and r' in the prime subgroup, r' = Σ [s'_i]P_i is
+	// equivalent to Σ [u_i]P_i + [v]r' = (0,1)
+	tables := make([][]tEd.Point, nbPoints+1)
+	digits := make([][]frontend.Variable, nbPoints+1)
+	for i := range tables {
+		p := r
+		if i < nbPoints {
+			p = pcs[i]
+		}
+		tables[i] = signedMultiples(api, curve, p, signs[i], msmWindow)
+		digits[i] = windows(api, bits[i], msmWindow)
+	}
+	res := msmLoop(api, curve, tables, digits, msmWindow)
+
+	api.AssertIsEqual(res.X, 0)
+	api.AssertIsEqual(res.Y, 1)
+
+	res = curve.Add(low, r)
+	return &res
+}
+
+// MultiScalarMulGLV computes the multi-scalar multiplication Σ [s_i]p_i on
+// the Bandersnatch curve in twisted Edwards form as:
+//
+//	Σ [s0_i]p_i + r' with
+//	Σ ([u_i,1]P_i + [u_i,2]φ(P_i)) + [v1]r' + [v2]φ(r') = (0,1)
+//
+// where P_i = [h]p_i, s_i = s0_i + h * s'_i with 0 ≤ s0_i < h the cofactor,
+// u_i,1 + λ*u_i,2 + s'_i*(v1 + λ*v2) == 0 mod r for all i,
+// (v1, v2) ≠ (0, 0) and all the values smaller than 2^n with
+// n = multiDecomposeGLVBits(r, N) ≈ N/(2N+2) * log2(r). It is
+// JointScalarMulGLV for N points, the relations being checked with
+// non-native arithmetic.
+//
+// It is MultiScalarMul with the √−2 endomorphism, which halves the length of
+// the loop. The 2-bit windows of the two components of each point index the
+// table [a]P_i + [b]φ(P_i), 0 ≤ a, b < 4, of the signed P_i and φ(P_i), and
+// the same for r'. These larger tables make it as costly as MultiScalarMul
+// from about four points on. The points are split into chunks of at most
+// msmMaxPointsGLV(r) points, for which the bounds ensure
+// v1 + λ*v2 ≠ 0 mod r.
+func MultiScalarMulGLV(api frontend.API, points []*tEd.Point, scalars []frontend.Variable) *tEd.Point {
+	if len(points) != len(scalars) {
+		panic("MultiScalarMulGLV: different numbers of points and scalars")
+	}
+	// get edwards curve curve
+	curve, err := tEd.NewEdCurve(api, twistededwards.BLS12_381_BANDERSNATCH)
+	if err != nil {
+		return nil
+	}
+	params := mustCurveParams(twistededwards.BLS12_381_BANDERSNATCH)
+	if len(points) == 0 {
+		return &tEd.Point{X: 0, Y: 1}
+	}
+	if max := msmMaxPointsGLV(params.Order); len(points) > max {
+		return msmChunks(curve, points, scalars, max, func(points []*tEd.Point, scalars []frontend.Variable) *tEd.Point {
+			return MultiScalarMulGLV(api, points, scalars)
+		})
+	}
+	nbPoints := len(points)
+
+	low, pcs, sBits, r := msmSplit(api, curve, points, scalars, params)
+
+	// the hint allows to decompose the scalars s'_i into u_i,1, u_i,2, v1
+	// and v2 such that u_i,1 + λ*u_i,2 + s'_i * (v1 + λ*v2) == 0 mod Order for
+	// all i.
+	inputs := make([]frontend.Variable, nbPoints+1)
+	for i := range sBits {
+		inputs[i] = api.FromBinary(sBits[i]...)
+	}
+	inputs[nbPoints] = params.Lambda
+	s, err := api.NewHint(msmDecomposeGLV, 4*(nbPoints+1), inputs...)
+	if err != nil {
+		// err is non-nil only for invalid number of inputs
+		panic(err)
+	}
+	v := 2 * nbPoints // index of v1, v2 being at v+1
+	signs := s[v+2:]
+
+	// all the values are smaller than 2^n
+	n := multiDecomposeGLVBits(params.Order, nbPoints)
+	bits := make([][]frontend.Variable, v+2)
+	for i := range bits {
+		bits[i] = api.ToBinary(s[i], n)
+	}
+
+	// check all the relations on the bits and signs used below, with the
+	// same v1 + λ*v2, which is also checked to be non-zero
+	for i := range sBits {
+		checkHalfGCDZZ2(api, sBits[i], params.Lambda,
+			[4][]frontend.Variable{bits[2*i], bits[2*i+1], bits[v], bits[v+1]},
+			[4]frontend.Variable{signs[2*i], signs[2*i+1], signs[v], signs[v+1]},
+		)
+	}
+
+	// with the P_i and r' in the prime subgroup, r' = Σ [s'_i]P_i is
+	// equivalent to the relation above
+	tables := make([][]tEd.Point, nbPoints+1)
+	digits := make([][]frontend.Variable, nbPoints+1)
+	for i := range tables {
+		p := r
+		if i < nbPoints {
+			p = pcs[i]
+		}
+		tables[i] = glvTable(api, curve, &p, signs[2*i], signs[2*i+1])
+		digits[i] = glvDigits(api, bits[2*i], bits[2*i+1])
+	}
+	res := msmLoop(api, curve, tables, digits, msmWindowGLV)
+
+	api.AssertIsEqual(res.X, 0)
+	api.AssertIsEqual(res.Y, 1)
+
+	res = curve.Add(low, r)
+	return &res
+}
+
+// msmMaxPoints returns the largest number N ≤ msmMaxChunk of points for
+// which the values of msmDecompose fit in log2(r) - 1 bits, so that |v| < r
+// and v ≠ 0 implies v ≠ 0 mod r.
+func msmMaxPoints(r *big.Int) int {
+	n := 1
+	for n < msmMaxChunk && multiDecomposeBits(r, n+1) < r.BitLen() {
+		n++
+	}
+	return n
+}
+
+// msmMaxPointsGLV returns the largest number N ≤ msmMaxChunkGLV of points
+// for which the values of msmDecomposeGLV are smaller than 2^n with 2n + 3 ≤ log2(r), so
+// that v1² + 2*v2² < 3 * 2^(2n) < r. As the norm of the non-zero vectors of
+// the lattice {(x, y) : x + λy == 0 mod r} is at least r, (v1, v2) ≠ (0, 0)
+// then implies v1 + λ*v2 ≠ 0 mod r.
+func msmMaxPointsGLV(r *big.Int) int {
+	n := 1
+	for n < msmMaxChunkGLV && 2*multiDecomposeGLVBits(r, n+1)+3 <= r.BitLen() {
+		n++
+	}
+	return n
+}
+
+// msmChunks returns the sum of msm over balanced chunks of at most max
+// points.
+func msmChunks(curve tEd.Curve, points []*tEd.Point, scalars []frontend.Variable, max int, msm func([]*tEd.Point, []frontend.Variable) *tEd.Point) *tEd.Point {
+	nbChunks := (len(points) + max - 1) / max
+	res := tEd.Point{X: 0, Y: 1}
+	for c := 0; c < nbChunks; c++ {
+		lo, hi := c*len(points)/nbChunks, (c+1)*len(points)/nbChunks
+		res = curve.Add(res, *msm(points[lo:hi], scalars[lo:hi]))
+	}
+	return &res
+}
+
+// msmSplit splits the scalars with splitScalar and hints the high part
+// r' = Σ [s'_i]P_i as [h]w for a hinted point w, so that r' is in the prime
+// subgroup whatever the hints. It returns Σ [s0_i]p_i, the points P_i, the
+// bits of the s'_i and r'.
+func msmSplit(api frontend.API, curve tEd.Curve, points []*tEd.Point, scalars []frontend.Variable, params *curveParams) (low tEd.Point, pcs []tEd.Point, sBits [][]frontend.Variable, r tEd.Point) {
+	low = tEd.Point{X: 0, Y: 1}
+	pcs = make([]tEd.Point, len(points))
+	sBits = make([][]frontend.Variable, len(points))
+	w := tEd.Point{X: 0, Y: 1}
+	for i := range points {
+		var lowI tEd.Point
+		lowI, pcs[i], sBits[i] = splitScalar(api, curve, points[i], scalars[i], params.Cofactor)
+		low = curve.Add(low, lowI)
+		w = curve.Add(w, scalarMulCofactorInverse(api, &pcs[i], api.FromBinary(sBits[i]...), params))
+	}
+	return low, pcs, sBits, clearCofactor(api, curve, w, params)
+}
+
+// signedMultiples returns the multiples [k]p for 0 ≤ k < 2^w, with p negated
+// if isNeg is 1.
+func signedMultiples(api frontend.API, curve tEd.Curve, p tEd.Point, isNeg frontend.Variable, w int) []tEd.Point {
+	p.X = api.Select(isNeg, api.Neg(p.X), p.X)
+	table := make([]tEd.Point, 1<<w)
+	table[0] = tEd.Point{X: 0, Y: 1}
+	table[1] = p
+	for k := 2; k < len(table); k++ {
+		table[k] = curve.Add(table[k-1], p)
+	}
+	return table
+}
+
+// glvTable returns the table [a]p + [b]φ(p) for 0 ≤ a, b < 2^w at index
+// a + 2^w*b, w = msmWindowGLV, with p and φ(p) negated if isNeg1 and isNeg2
+// are 1 respectively.
+func glvTable(api frontend.API, curve tEd.Curve, p *tEd.Point, isNeg1, isNeg2 frontend.Variable) []tEd.Point {
+	var p1, p2 tEd.Point
+	p1.X = api.Select(isNeg1, api.Neg(p.X), p.X)
+	p1.Y = p.Y
+	p2 = *phi(api, p)
+	p2.X = api.Select(isNeg2, api.Neg(p2.X), p2.X)
+
+	const size = 1 << msmWindowGLV
+	table := make([]tEd.Point, size*size)
+	table[0] = tEd.Point{X: 0, Y: 1}
+	for a := 1; a < size; a++ {
+		table[a] = curve.Add(table[a-1], p1)
+	}
+	for b := 1; b < size; b++ {
+		for a := 0; a < size; a++ {
+			table[a+size*b] = curve.Add(table[a+size*(b-1)], p2)
+		}
+	}
+	return table
+}
+
+// glvDigits returns the indices a + 2^w*b of glvTable for the msmWindowGLV-bit
+// windows a and b of the little-endian bits b1 and b2.
+func glvDigits(api frontend.API, b1, b2 []frontend.Variable) []frontend.Variable {
+	const size = 1 << msmWindowGLV
+	w1 := windows(api, b1, msmWindowGLV)
+	w2 := windows(api, b2, msmWindowGLV)
+	digits := make([]frontend.Variable, len(w1))
+	for j := range digits {
+		digits[j] = api.Add(w1[j], api.Mul(w2[j], size))
+	}
+	return digits
+}
+
+// msmLoop returns Σ_i Σ_j [2^(w*j)]tables[i][digits[i][j]] with one
+// double-and-add loop over the windows j. All the tables go in one
+// logderivlookup table, the table of the i-th point at offset
+// i*len(tables[i]). The tables must have the same size and the digits the
+// same length.
+func msmLoop(api frontend.API, curve tEd.Curve, tables [][]tEd.Point, digits [][]frontend.Variable, w int) tEd.Point {
+	if len(tables) == 0 {
+		return tEd.Point{X: 0, Y: 1}
+	}
+	tblX := logderivlookup.New(api)
+	tblY := logderivlookup.New(api)
+	for i := range tables {
+		for j := range tables[i] {
+			tblX.Insert(tables[i][j].X)
+			tblY.Insert(tables[i][j].Y)
+		}
+	}
+
+	nbWindows := len(digits[0])
+	var res tEd.Point
+	for j := nbWindows - 1; j >= 0; j-- {
+		inds := make([]frontend.Variable, len(tables))
+		for i := range tables {
+			inds[i] = api.Add(digits[i][j], i*len(tables[i]))
+		}
+		xs := tblX.Lookup(inds...)
+		ys := tblY.Lookup(inds...)
+		if j == nbWindows-1 {
+			res = tEd.Point{X: xs[0], Y: ys[0]}
+		} else {
+			for k := 0; k < w; k++ {
+				res = curve.Double(res)
+			}
+			res = curve.Add(res, tEd.Point{X: xs[0], Y: ys[0]})
+		}
+		for i := 1; i < len(tables); i++ {
+			res = curve.Add(res, tEd.Point{X: xs[i], Y: ys[i]})
+		}
+	}
+	return res
+}
+
+// windows returns the w-bit windows of the little-endian bits, least
+// significant first. The last window is padded with zeros.
+func windows(api frontend.API, bits []frontend.Variable, w int) []frontend.Variable {
+	res := make([]frontend.Variable, (len(bits)+w-1)/w)
+	for j := range res {
+		res[j] = 0
+		for k := w - 1; k >= 0; k-- {
+			res[j] = api.Mul(res[j], 2)
+			if j*w+k < len(bits) {
+				res[j] = api.Add(res[j], bits[j*w+k])
+			}
+		}
+	}
+	return res
+}
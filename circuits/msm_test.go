@@ -0,0 +1,353 @@
+package circuits
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/profile"
+	tEd "github.com/consensys/gnark/std/algebra/native/twistededwards"
+)
+
+type multiScalarMul struct {
+	curveID twistededwards.ID
+	glv     bool
+	P       []tEd.Point
+	S       []frontend.Variable
+	R       tEd.Point
+}
+
+func newMultiScalarMul(id twistededwards.ID, glv bool, n int) *multiScalarMul {
+	return &multiScalarMul{curveID: id, glv: glv, P: make([]tEd.Point, n), S: make([]frontend.Variable, n)}
+}
+
+func (circuit *multiScalarMul) Define(api frontend.API) error {
+	points := make([]*tEd.Point, len(circuit.P))
+	for i := range points {
+		points[i] = &circuit.P[i]
+	}
+	var res *tEd.Point
+	if circuit.glv {
+		res = MultiScalarMulGLV(api, points, circuit.S)
+	} else {
+		res = MultiScalarMul(api, points, circuit.S, circuit.curveID)
+	}
+	api.AssertIsEqual(res.X, circuit.R.X)
+	api.AssertIsEqual(res.Y, circuit.R.Y)
+
+	return nil
+}
+
+// multiScalarMulNative returns Σ [s_i]p_i.
+func multiScalarMulNative(t *testing.T, id twistededwards.ID, points [][2]*big.Int, scalars []*big.Int) [2]*big.Int {
+	res := [2]*big.Int{big.NewInt(0), big.NewInt(1)}
+	for i := range points {
+		var r [2]*big.Int
+		r[0], r[1] = new(big.Int), new(big.Int)
+		if err := scalarMulNative(id, points[i][0], points[i][1], scalars[i], r[0], r[1]); err != nil {
+			t.Fatal(err)
+		}
+		res = addNative(id, res, r)
+	}
+	return res
+}
+
+func TestMultiScalarMul(t *testing.T) {
+	const n = 3
+	type variant struct {
+		name string
+		id   twistededwards.ID
+		glv  bool
+	}
+	var variants []variant
+	for _, id := range curveIDs {
+		variants = append(variants, variant{curveNames[id], id, false})
+	}
+	variants = append(variants, variant{"GLV", twistededwards.BLS12_381_BANDERSNATCH, true})
+	for _, v := range variants {
+		v := v
+		t.Run(v.name, func(t *testing.T) {
+			t.Parallel()
+			id := v.id
+			params := mustCurveParams(id)
+			ccs, err := frontend.Compile(params.Field, scs.NewBuilder, newMultiScalarMul(id, v.glv, n))
+			if err != nil {
+				t.Fatal(err)
+			}
+			points := curvePoints(id, n)
+			if id == twistededwards.BLS12_381_BANDERSNATCH {
+				// on Bandersnatch [s]p may be at infinity for p outside the
+				// prime subgroup, use [h]p instead
+				for i := range points {
+					if err := scalarMulNative(id, points[i][0], points[i][1], params.Cofactor, points[i][0], points[i][1]); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			torsion := smallOrderPoint(id)
+			identity := [2]*big.Int{big.NewInt(0), big.NewInt(1)}
+			randomScalars := func() []*big.Int {
+				res := make([]*big.Int, n)
+				for i := range res {
+					res[i], _ = rand.Int(rand.Reader, params.Order)
+				}
+				return res
+			}
+
+			type testCase struct {
+				name    string
+				points  [][2]*big.Int
+				scalars []*big.Int
+			}
+			cases := []testCase{
+				{"random", points, randomScalars()},
+				{"zero scalars", points, []*big.Int{big.NewInt(0), big.NewInt(0), big.NewInt(0)}},
+				{"edge scalars", points, []*big.Int{big.NewInt(1), new(big.Int).Sub(params.Order, big.NewInt(1)), new(big.Int).Set(params.Order)}},
+				{"identity", [][2]*big.Int{identity, points[1], identity}, randomScalars()},
+				{"repeated point", [][2]*big.Int{points[0], points[0], points[0]}, randomScalars()},
+			}
+			if id != twistededwards.BLS12_381_BANDERSNATCH {
+				// on Bandersnatch the low parts of points outside the prime
+				// subgroup may sum up to a point at infinity
+				cases = append(cases, testCase{"small order", [][2]*big.Int{torsion, points[1], addNative(id, points[2], torsion)}, randomScalars()})
+			}
+			for _, tc := range cases {
+				r := multiScalarMulNative(t, id, tc.points, tc.scalars)
+				for _, expected := range []struct {
+					r     [2]*big.Int
+					valid bool
+				}{
+					{r, true},
+					{addNative(id, r, torsion), false},
+				} {
+					assignment := newMultiScalarMul(id, v.glv, n)
+					for i := range tc.points {
+						assignment.P[i] = tEd.Point{X: tc.points[i][0], Y: tc.points[i][1]}
+						assignment.S[i] = tc.scalars[i]
+					}
+					assignment.R = tEd.Point{X: expected.r[0], Y: expected.r[1]}
+					w, err := frontend.NewWitness(assignment, params.Field)
+					if err != nil {
+						t.Fatal(err)
+					}
+					err = ccs.IsSolved(w)
+					if expected.valid && err != nil {
+						t.Fatalf("%s: %v", tc.name, err)
+					}
+					if !expected.valid && err == nil {
+						t.Fatalf("%s: a wrong result was accepted", tc.name)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMultiScalarMulChunks(t *testing.T) {
+	id := twistededwards.BLS12_381_BANDERSNATCH
+	params := mustCurveParams(id)
+	for _, glv := range []bool{false, true} {
+		// one more point than a relation can hold
+		n := msmMaxPoints(params.Order) + 1
+		if glv {
+			n = msmMaxPointsGLV(params.Order) + 1
+		}
+		points := curvePoints(id, n)
+		scalars := make([]*big.Int, n)
+		for i := range scalars {
+			scalars[i], _ = rand.Int(rand.Reader, params.Order)
+		}
+		r := multiScalarMulNative(t, id, points, scalars)
+
+		ccs, err := frontend.Compile(params.Field, scs.NewBuilder, newMultiScalarMul(id, glv, n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, expected := range []struct {
+			r     [2]*big.Int
+			valid bool
+		}{
+			{r, true},
+			{addNative(id, r, smallOrderPoint(id)), false},
+		} {
+			assignment := newMultiScalarMul(id, glv, n)
+			for i := range points {
+				assignment.P[i] = tEd.Point{X: points[i][0], Y: points[i][1]}
+				assignment.S[i] = scalars[i]
+			}
+			assignment.R = tEd.Point{X: expected.r[0], Y: expected.r[1]}
+			w, err := frontend.NewWitness(assignment, params.Field)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = ccs.IsSolved(w)
+			if expected.valid && err != nil {
+				t.Fatalf("GLV=%t: %v", glv, err)
+			}
+			if !expected.valid && err == nil {
+				t.Fatalf("GLV=%t: a wrong result was accepted", glv)
+			}
+		}
+	}
+}
+
+// TestMultiScalarMulMaliciousHints checks that a prover replacing the hinted
+// result and its decomposition cannot make MultiScalarMul or
+// MultiScalarMulGLV accept a wrong result.
+func TestMultiScalarMulMaliciousHints(t *testing.T) {
+	const n = 2
+	id := twistededwards.BLS12_381_BANDERSNATCH
+	params := mustCurveParams(id)
+	points := curvePoints(id, n)
+	scalars := make([]*big.Int, n)
+	for i := range scalars {
+		scalars[i], _ = rand.Int(rand.Reader, params.Order)
+	}
+	r := multiScalarMulNative(t, id, points, scalars)
+	sHi := []*big.Int{new(big.Int).Div(scalars[0], params.Cofactor), new(big.Int).Div(scalars[1], params.Cofactor)}
+
+	// swap s'_0 and s'_1 in the hinted result and in its decomposition, which
+	// are then consistent with each other but not with the scalars
+	swappedScalarMul := func(mod *big.Int, inputs, outputs []*big.Int) error {
+		in := append([]*big.Int{}, inputs...)
+		for i := range sHi {
+			if inputs[2].Cmp(sHi[i]) == 0 {
+				in[2] = sHi[1-i]
+			}
+		}
+		return scalarMulHint(mod, in, outputs)
+	}
+	swapped := func(h solver.Hint) solver.Hint {
+		return func(mod *big.Int, inputs, outputs []*big.Int) error {
+			in := append([]*big.Int{}, inputs...)
+			in[0], in[1] = inputs[1], inputs[0]
+			return h(mod, in, outputs)
+		}
+	}
+	swappedScalars := []*big.Int{
+		new(big.Int).Add(new(big.Int).Mul(sHi[1], params.Cofactor), new(big.Int).Mod(scalars[0], params.Cofactor)),
+		new(big.Int).Add(new(big.Int).Mul(sHi[0], params.Cofactor), new(big.Int).Mod(scalars[1], params.Cofactor)),
+	}
+	rSwapped := multiScalarMulNative(t, id, points, swappedScalars)
+
+	// add P_0 = [h]p_0 to the hinted result and decompose it as zero
+	shifted := func(mod *big.Int, inputs, outputs []*big.Int) error {
+		in := append([]*big.Int{}, inputs...)
+		if inputs[2].Cmp(sHi[0]) == 0 {
+			in[2] = new(big.Int).Add(sHi[0], big.NewInt(1))
+		}
+		return scalarMulHint(mod, in, outputs)
+	}
+	zero := func(_ *big.Int, _, outputs []*big.Int) error {
+		for i := range outputs {
+			outputs[i].SetUint64(0)
+		}
+		return nil
+	}
+	var pc [2]*big.Int
+	pc[0], pc[1] = new(big.Int), new(big.Int)
+	if err := scalarMulNative(id, points[0][0], points[0][1], params.Cofactor, pc[0], pc[1]); err != nil {
+		t.Fatal(err)
+	}
+	rShifted := addNative(id, r, pc)
+
+	for _, glv := range []bool{false, true} {
+		decompose := msmDecompose
+		if glv {
+			decompose = msmDecomposeGLV
+		}
+		ccs, err := frontend.Compile(params.Field, scs.NewBuilder, newMultiScalarMul(id, glv, n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tc := range []struct {
+			name  string
+			r     [2]*big.Int
+			opts  []solver.Option
+			valid bool
+		}{
+			{"honest", r, nil, true},
+			{"swapped scalars", rSwapped, []solver.Option{
+				solver.OverrideHint(solver.GetHintID(scalarMulHint), swappedScalarMul),
+				solver.OverrideHint(solver.GetHintID(decompose), swapped(decompose)),
+			}, false},
+			{"zero decomposition", rShifted, []solver.Option{
+				solver.OverrideHint(solver.GetHintID(scalarMulHint), shifted),
+				solver.OverrideHint(solver.GetHintID(decompose), zero),
+			}, false},
+		} {
+			assignment := newMultiScalarMul(id, glv, n)
+			for i := range points {
+				assignment.P[i] = tEd.Point{X: points[i][0], Y: points[i][1]}
+				assignment.S[i] = scalars[i]
+			}
+			assignment.R = tEd.Point{X: tc.r[0], Y: tc.r[1]}
+			w, err := frontend.NewWitness(assignment, params.Field)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = ccs.IsSolved(w, tc.opts...)
+			if tc.valid && err != nil {
+				t.Fatalf("GLV=%t %s: %v", glv, tc.name, err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("GLV=%t %s: the malicious hints were accepted", glv, tc.name)
+			}
+		}
+	}
+}
+
+type independentScalarMuls struct {
+	P []tEd.Point
+	S []frontend.Variable
+	R tEd.Point
+}
+
+func (circuit *independentScalarMuls) Define(api frontend.API) error {
+	curve, err := tEd.NewEdCurve(api, twistededwards.BLS12_381_BANDERSNATCH)
+	if err != nil {
+		return err
+	}
+	res := tEd.Point{X: 0, Y: 1}
+	for i := range circuit.P {
+		res = curve.Add(res, *ScalarMulGLVAndFakeGLVLog(api, &circuit.P[i], circuit.S[i]))
+	}
+	api.AssertIsEqual(res.X, circuit.R.X)
+	api.AssertIsEqual(res.Y, circuit.R.Y)
+
+	return nil
+}
+
+// BenchmarkMultiScalarMulBandersnatch prints the number of constraints of
+// MultiScalarMul, MultiScalarMulGLV and N independent
+// ScalarMulGLVAndFakeGLVLog on Bandersnatch.
+func BenchmarkMultiScalarMulBandersnatch(b *testing.B) {
+	id := twistededwards.BLS12_381_BANDERSNATCH
+	field := mustCurveParams(id).Field
+	for _, n := range []int{1, 2, 8, 32} {
+		for _, c := range []struct {
+			name    string
+			circuit frontend.Circuit
+		}{
+			{"MSM", newMultiScalarMul(id, false, n)},
+			{"MSM GLV", newMultiScalarMul(id, true, n)},
+			{"independent 4D logup", &independentScalarMuls{P: make([]tEd.Point, n), S: make([]frontend.Variable, n)}},
+		} {
+			p := profile.Start()
+			_, _ = frontend.Compile(field, scs.NewBuilder, c.circuit)
+			p.Stop()
+			fmt.Printf("Bandersnatch N=%d %s (scs): %d\n", n, c.name, p.NbConstraints())
+
+			p = profile.Start()
+			_, _ = frontend.Compile(field, r1cs.NewBuilder, c.circuit)
+			p.Stop()
+			fmt.Printf("Bandersnatch N=%d %s (r1cs): %d\n", n, c.name, p.NbConstraints())
+		}
+	}
+}
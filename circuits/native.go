@@ -0,0 +1,122 @@
+package circuits
+
+import (
+	"errors"
+	"math/big"
+
+	tbls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/twistededwards"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/bandersnatch"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	jubjub "github.com/consensys/gnark-crypto/ecc/bls12-381/twistededwards"
+	tbls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/twistededwards"
+	tbls24317 "github.com/consensys/gnark-crypto/ecc/bls24-317/twistededwards"
+	tbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	tbw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/twistededwards"
+	tbw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/twistededwards"
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+)
+
+// scalarMulNative sets (rx, ry) to [s](x, y) on the twisted Edwards curve id,
+// using gnark-crypto. It is the reference the hints and tests share, and is
+// correct for any point of the curve and any non-negative s.
+func scalarMulNative(id twistededwards.ID, x, y, s, rx, ry *big.Int) error {
+	switch id {
+	case twistededwards.BN254:
+		var p tbn254.PointAffine
+		p.X.SetBigInt(x)
+		p.Y.SetBigInt(y)
+		p.ScalarMultiplication(&p, s)
+		p.X.BigInt(rx)
+		p.Y.BigInt(ry)
+	case twistededwards.BLS12_377:
+		var p tbls12377.PointAffine
+		p.X.SetBigInt(x)
+		p.Y.SetBigInt(y)
+		p.ScalarMultiplication(&p, s)
+		p.X.BigInt(rx)
+		p.Y.BigInt(ry)
+	case twistededwards.BLS12_381:
+		var p jubjub.PointAffine
+		p.X.SetBigInt(x)
+		p.Y.SetBigInt(y)
+		p.ScalarMultiplication(&p, s)
+		p.X.BigInt(rx)
+		p.Y.BigInt(ry)
+	case twistededwards.BLS12_381_BANDERSNATCH:
+		// the GLV scalar multiplication of gnark-crypto is only correct on
+		// the prime subgroup, so use a double-and-add instead.
+		var p bandersnatch.PointAffine
+		var pp, res bandersnatch.PointProj
+		p.X.SetBigInt(x)
+		p.Y.SetBigInt(y)
+		pp.FromAffine(&p)
+		res.Y.SetOne()
+		res.Z.SetOne()
+		for i := s.BitLen() - 1; i >= 0; i-- {
+			res.Double(&res)
+			if s.Bit(i) == 1 {
+				res.Add(&res, &pp)
+			}
+		}
+		p.FromProj(&res)
+		p.X.BigInt(rx)
+		p.Y.BigInt(ry)
+	case twistededwards.BW6_761:
+		var p tbw6761.PointAffine
+		p.X.SetBigInt(x)
+		p.Y.SetBigInt(y)
+		p.ScalarMultiplication(&p, s)
+		p.X.BigInt(rx)
+		p.Y.BigInt(ry)
+	case twistededwards.BW6_633:
+		var p tbw6633.PointAffine
+		p.X.SetBigInt(x)
+		p.Y.SetBigInt(y)
+		p.ScalarMultiplication(&p, s)
+		p.X.BigInt(rx)
+		p.Y.BigInt(ry)
+	case twistededwards.BLS24_315:
+		var p tbls24315.PointAffine
+		p.X.SetBigInt(x)
+		p.Y.SetBigInt(y)
+		p.ScalarMultiplication(&p, s)
+		p.X.BigInt(rx)
+		p.Y.BigInt(ry)
+	case twistededwards.BLS24_317:
+		var p tbls24317.PointAffine
+		p.X.SetBigInt(x)
+		p.Y.SetBigInt(y)
+		p.ScalarMultiplication(&p, s)
+		p.X.BigInt(rx)
+		p.Y.BigInt(ry)
+	default:
+		return errors.New("unknown twisted edwards curve id")
+	}
+	return nil
+}
+
+// phiNative returns the image of p by the √−2 endomorphism of Bandersnatch
+// with coefficients endo, see phi:
+//
+//	φ(x, y) = ((1-y²)·c1 / (xy), (y²+c0)·c0 / (y²-c0))
+//
+// p must be in the prime subgroup, where (0,1) is the only point with x = 0.
+// It is mapped to itself.
+func phiNative(p *bandersnatch.PointAffine, endo [2]*big.Int) bandersnatch.PointAffine {
+	var res bandersnatch.PointAffine
+	if p.X.IsZero() {
+		res.Set(p)
+		return res
+	}
+	var c0, c1, xy, yy, num, den fr.Element
+	c0.SetBigInt(endo[0])
+	c1.SetBigInt(endo[1])
+	xy.Mul(&p.X, &p.Y)
+	yy.Square(&p.Y)
+	num.SetOne().Sub(&num, &yy).Mul(&num, &c1)
+	res.X.Div(&num, &xy)
+	num.Add(&yy, &c0).Mul(&num, &c0)
+	den.Sub(&yy, &c0)
+	res.Y.Div(&num, &den)
+	return res
+}
@@ -0,0 +1,132 @@
+package circuits
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/bandersnatch"
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	tEd "github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/yelhousni/jubjub-vs-bandersnatch/zz2"
+)
+
+// curveParams holds the native parameters of a twisted Edwards curve that the
+// hints and the circuits need. The entries of the registry are computed once
+// and must not be modified.
+type curveParams struct {
+	ID       twistededwards.ID
+	Field    *big.Int // the SNARK field, i.e. the base field of the curve
+	Order    *big.Int // the order r of the prime subgroup
+	Cofactor *big.Int
+
+	// The following are nil for curves without an efficient endomorphism.
+
+	// Lambda is the eigenvalue of the endomorphism φ on the prime subgroup.
+	Lambda *big.Int
+	// Endo are the coefficients of φ, see phi.
+	Endo [2]*big.Int
+	// Lattice is the GLV lattice of (Order, Lambda).
+	Lattice *ecc.Lattice
+	// Pi generates the prime ideal above Order in Z[√−2], with
+	// π0 + λ * π1 = 0 mod r.
+	Pi *zz2.ComplexNumber
+}
+
+// HasEndomorphism reports whether the curve has an efficient endomorphism.
+func (p *curveParams) HasEndomorphism() bool {
+	return p.Lambda != nil
+}
+
+var (
+	curveParamsOnce     sync.Once
+	curveParamsRegistry map[twistededwards.ID]*curveParams
+)
+
+// getCurveParams returns the registry entry of the curve id.
+func getCurveParams(id twistededwards.ID) (*curveParams, error) {
+	curveParamsOnce.Do(initCurveParams)
+	p, ok := curveParamsRegistry[id]
+	if !ok {
+		return nil, errors.New("unknown twisted edwards curve id")
+	}
+	return p, nil
+}
+
+// mustCurveParams is like getCurveParams but panics on unknown curves.
+func mustCurveParams(id twistededwards.ID) *curveParams {
+	p, err := getCurveParams(id)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// endomorphismParams returns the parameters of the curve with an efficient
+// endomorphism over the SNARK field mod. Only Bandersnatch has one.
+func endomorphismParams(mod *big.Int) (*curveParams, error) {
+	p := mustCurveParams(twistededwards.BLS12_381_BANDERSNATCH)
+	if mod.Cmp(p.Field) != 0 {
+		return nil, errors.New("no efficient endomorphism is available on this curve")
+	}
+	return p, nil
+}
+
+// curveIDs lists the twisted Edwards curves of gnark-crypto that have a
+// gnark circuit counterpart.
+var curveIDs = []twistededwards.ID{
+	twistededwards.BN254,
+	twistededwards.BLS12_377,
+	twistededwards.BLS12_381,
+	twistededwards.BLS12_381_BANDERSNATCH,
+	twistededwards.BW6_761,
+	twistededwards.BW6_633,
+	twistededwards.BLS24_315,
+	twistededwards.BLS24_317,
+}
+
+func initCurveParams() {
+	curveParamsRegistry = make(map[twistededwards.ID]*curveParams)
+	for _, id := range curveIDs {
+		params, err := tEd.GetCurveParams(id)
+		if err != nil {
+			panic(err)
+		}
+		field, err := tEd.GetSnarkField(id)
+		if err != nil {
+			panic(err)
+		}
+		curveParamsRegistry[id] = &curveParams{
+			ID:       id,
+			Field:    field,
+			Order:    params.Order,
+			Cofactor: params.Cofactor,
+		}
+	}
+
+	// Bandersnatch √−2 endomorphism. The coefficients of φ are the ones of
+	// gnark-crypto, which does not export them; λ is derived from them as the
+	// root of j² + 2 mod r with φ(G) = [λ]G.
+	p := curveParamsRegistry[twistededwards.BLS12_381_BANDERSNATCH]
+	p.Endo[0], _ = new(big.Int).SetString("37446463827641770816307242315180085052603635617490163568005256780843403514036", 10)
+	p.Endo[1], _ = new(big.Int).SetString("49199877423542878313146170939139662862850515542392585932876811575731455068989", 10)
+	base := bandersnatch.GetEdwardsCurve().Base
+	phiBase := phiNative(&base, p.Endo)
+	lambda, err := zz2.Lambda(p.Order, func(lambda *big.Int) bool {
+		var lambdaBase bandersnatch.PointAffine
+		lambdaBase.ScalarMultiplication(&base, lambda)
+		return lambdaBase.Equal(&phiBase)
+	})
+	if err != nil {
+		panic(err)
+	}
+	p.Lambda = lambda
+	p.Lattice = new(ecc.Lattice)
+	ecc.PrecomputeLattice(p.Order, p.Lambda, p.Lattice)
+	pi, err := zz2.PrimeIdealGenerator(p.Order, p.Lambda)
+	if err != nil {
+		panic(err)
+	}
+	p.Pi = pi
+}
@@ -0,0 +1,93 @@
+package circuits
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/bandersnatch"
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/yelhousni/jubjub-vs-bandersnatch/zz2"
+)
+
+func TestCurveParamsRegistry(t *testing.T) {
+	for _, id := range curveIDs {
+		p, err := getCurveParams(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.ID != id || !p.Order.ProbablyPrime(10) || p.Cofactor.Sign() <= 0 {
+			t.Fatalf("curve %d: bad parameters", id)
+		}
+		if p.HasEndomorphism() != (id == twistededwards.BLS12_381_BANDERSNATCH) {
+			t.Fatalf("curve %d: unexpected endomorphism", id)
+		}
+		if q, _ := getCurveParams(id); q != p {
+			t.Fatalf("curve %d: the parameters are recomputed", id)
+		}
+	}
+	if _, err := getCurveParams(twistededwards.ID(255)); err == nil {
+		t.Fatal("expected an error for an unknown curve")
+	}
+}
+
+func TestHalfGCDBoundFitsRangeCheck(t *testing.T) {
+	// the circuits range-check the half-GCD outputs on BitLen/4 + 9 bits.
+	for _, id := range curveIDs {
+		r := mustCurveParams(id).Order
+		n := uint(r.BitLen()/4 + 9)
+		if bound := zz2.HalfGCDBound(r); bound.Cmp(new(big.Int).Lsh(big.NewInt(1), n)) >= 0 {
+			t.Fatalf("curve %d: bound %s does not fit on %d bits", id, bound, n)
+		}
+	}
+}
+
+func TestCurveParamsBandersnatchEndomorphism(t *testing.T) {
+	p := mustCurveParams(twistededwards.BLS12_381_BANDERSNATCH)
+	r := p.Order
+	curve := bandersnatch.GetEdwardsCurve()
+	if r.Cmp(&curve.Order) != 0 || p.Cofactor.Cmp(curve.Cofactor.BigInt(new(big.Int))) != 0 {
+		t.Fatal("order or cofactor do not match gnark-crypto")
+	}
+
+	// λ² = -2 mod r
+	var l2 big.Int
+	l2.Mul(p.Lambda, p.Lambda).Add(&l2, big.NewInt(2)).Mod(&l2, r)
+	if l2.Sign() != 0 {
+		t.Fatal("λ² != -2 mod r")
+	}
+
+	// V1[0] + λ*V1[1] = 0 mod r and π0 + λ*π1 = 0 mod r with N(π) = r
+	var v big.Int
+	v.Mul(&p.Lattice.V1[1], p.Lambda).Add(&v, &p.Lattice.V1[0]).Mod(&v, r)
+	if v.Sign() != 0 {
+		t.Fatal("V1 is not in the GLV lattice")
+	}
+	if p.Pi.Eval(p.Lambda, r).Sign() != 0 || p.Pi.Norm().Cmp(r) != 0 {
+		t.Fatal("π does not generate the prime ideal above r")
+	}
+
+	// φ(P) = [λ]P
+	var base, lambdaBase bandersnatch.PointAffine
+	base.Set(&curve.Base)
+	lambdaBase.ScalarMultiplication(&base, p.Lambda)
+	phiBase := phiNative(&base, p.Endo)
+	if !phiBase.Equal(&lambdaBase) {
+		t.Fatal("φ(P) != [λ]P")
+	}
+
+	// φ(φ(P)) = [-2]P pins the coefficients of φ independently of λ
+	for i := 0; i < 4; i++ {
+		s, _ := rand.Int(rand.Reader, r)
+		var q, minusTwoQ bandersnatch.PointAffine
+		q.ScalarMultiplication(&base, s)
+		minusTwoQ.ScalarMultiplication(&q, new(big.Int).Sub(r, big.NewInt(2)))
+		phiQ := phiNative(&q, p.Endo)
+		if !phiQ.IsOnCurve() {
+			t.Fatal("φ(P) is not on the curve")
+		}
+		if phiPhiQ := phiNative(&phiQ, p.Endo); !phiPhiQ.Equal(&minusTwoQ) {
+			t.Fatal("φ(φ(P)) != [-2]P")
+		}
+	}
+}
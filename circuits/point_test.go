@@ -2,6 +2,7 @@ package circuits
 
 import (
 	"crypto/rand"
+	"math/big"
 	"testing"
 
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	tbls12381_bandersnatch "github.com/consensys/gnark-crypto/ecc/bls12-381/bandersnatch"
 	tbls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/twistededwards"
 	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/constraint/solver"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/consensys/gnark/frontend/cs/scs"
 	"github.com/consensys/gnark/profile"
@@ -103,6 +105,130 @@ func TestScalarMulGeneric2(t *testing.T) {
 		test.WithCurves(ecc.BLS12_381))
 }
 
+// curveNames are the names used in the test and benchmark outputs.
+var curveNames = map[twistededwards.ID]string{
+	twistededwards.BN254:                  "BabyJubjub (BN254)",
+	twistededwards.BLS12_377:              "BLS12-377 Ed",
+	twistededwards.BLS12_381:              "Jubjub",
+	twistededwards.BLS12_381_BANDERSNATCH: "Bandersnatch",
+	twistededwards.BW6_761:                "BW6-761 Ed",
+	twistededwards.BW6_633:                "BW6-633 Ed",
+	twistededwards.BLS24_315:              "BLS24-315 Ed",
+	twistededwards.BLS24_317:              "BLS24-317 Ed",
+}
+
+// snarkCurve returns the SNARK curve whose scalar field the twisted Edwards
+// curve id is defined over.
+func snarkCurve(id twistededwards.ID) ecc.ID {
+	field, err := tEd.GetSnarkField(id)
+	if err != nil {
+		panic(err)
+	}
+	for _, c := range ecc.Implemented() {
+		if c.ScalarField().Cmp(field) == 0 {
+			return c
+		}
+	}
+	panic("no SNARK curve for this twisted edwards curve")
+}
+
+// scalarMulAssignments returns a valid and an invalid assignment of P, R and S
+// for [S]P = R on the curve id, with P the base point.
+func scalarMulAssignments(t *testing.T, id twistededwards.ID) (p, r [2]*big.Int, s *big.Int) {
+	params, err := tEd.GetCurveParams(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ = rand.Int(rand.Reader, params.Order)
+	p = params.Base
+	r = [2]*big.Int{new(big.Int), new(big.Int)}
+	if err := scalarMulNative(id, p[0], p[1], s, r[0], r[1]); err != nil {
+		t.Fatal(err)
+	}
+	return p, r, s
+}
+
+func TestScalarMulGenericAllCurves(t *testing.T) {
+	for _, id := range curveIDs {
+		t.Run(curveNames[id], func(t *testing.T) {
+			assert := test.NewAssert(t)
+			p, r, s := scalarMulAssignments(t, id)
+			circuit := scalarMulGeneric{curveID: id}
+			validWitness := scalarMulGeneric{P: tEd.Point{X: p[0], Y: p[1]}, R: tEd.Point{X: r[0], Y: r[1]}, S: s}
+			invalidWitness := scalarMulGeneric{P: tEd.Point{X: r[0], Y: r[1]}, R: tEd.Point{X: p[0], Y: p[1]}, S: s}
+			assert.CheckCircuit(&circuit,
+				test.WithValidAssignment(&validWitness),
+				test.WithInvalidAssignment(&invalidWitness),
+				test.WithCurves(snarkCurve(id)))
+		})
+	}
+}
+
+type scalarMulGenericWindowed struct {
+	curveID twistededwards.ID
+	w       int
+	P       tEd.Point
+	R       tEd.Point
+	S       frontend.Variable
+}
+
+func (circuit *scalarMulGenericWindowed) Define(api frontend.API) error {
+	res := ScalarMulGenericWindowed(api, &circuit.P, circuit.S, circuit.curveID, circuit.w)
+	api.AssertIsEqual(res.X, circuit.R.X)
+	api.AssertIsEqual(res.Y, circuit.R.Y)
+
+	return nil
+}
+
+func TestScalarMulGenericWindowed(t *testing.T) {
+	for _, id := range []twistededwards.ID{twistededwards.BLS12_381, twistededwards.BLS12_381_BANDERSNATCH} {
+		for w := 2; w <= 6; w++ {
+			id, w := id, w
+			t.Run(fmt.Sprintf("%s/w=%d", curveNames[id], w), func(t *testing.T) {
+				t.Parallel()
+				params := mustCurveParams(id)
+				p, _, _ := scalarMulAssignments(t, id)
+				scalars := append(edgeScalars(params.Order), new(big.Int).Sub(params.Field, big.NewInt(1)))
+				for _, builder := range []frontend.NewBuilder{r1cs.NewBuilder, scs.NewBuilder} {
+					ccs, err := frontend.Compile(params.Field, builder, &scalarMulGenericWindowed{curveID: id, w: w})
+					if err != nil {
+						t.Fatal(err)
+					}
+					for _, s := range scalars {
+						r := [2]*big.Int{new(big.Int), new(big.Int)}
+						if err := scalarMulNative(id, p[0], p[1], s, r[0], r[1]); err != nil {
+							t.Fatal(err)
+						}
+						for _, expected := range []struct {
+							r     [2]*big.Int
+							valid bool
+						}{
+							{r, true},
+							{addNative(id, r, p), false},
+						} {
+							wit, err := frontend.NewWitness(&scalarMulGenericWindowed{
+								P: tEd.Point{X: p[0], Y: p[1]},
+								R: tEd.Point{X: expected.r[0], Y: expected.r[1]},
+								S: s,
+							}, params.Field)
+							if err != nil {
+								t.Fatal(err)
+							}
+							err = ccs.IsSolved(wit)
+							if expected.valid && err != nil {
+								t.Fatalf("s=%s: %v", s, err)
+							}
+							if !expected.valid && err == nil {
+								t.Fatalf("s=%s: a wrong result was accepted", s)
+							}
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
 type scalarMulFakeGLV struct {
 	curveID twistededwards.ID
 	P       tEd.Point
@@ -186,6 +312,197 @@ func TestScalarMulFakeGLV2(t *testing.T) {
 		test.WithCurves(ecc.BLS12_381))
 }
 
+func TestScalarMulFakeGLVAllCurves(t *testing.T) {
+	for _, id := range curveIDs {
+		t.Run(curveNames[id], func(t *testing.T) {
+			assert := test.NewAssert(t)
+			p, r, s := scalarMulAssignments(t, id)
+			circuit := scalarMulFakeGLV{curveID: id}
+			validWitness := scalarMulFakeGLV{P: tEd.Point{X: p[0], Y: p[1]}, R: tEd.Point{X: r[0], Y: r[1]}, S: s}
+			invalidWitness := scalarMulFakeGLV{P: tEd.Point{X: r[0], Y: r[1]}, R: tEd.Point{X: p[0], Y: p[1]}, S: s}
+			assert.CheckCircuit(&circuit,
+				test.WithValidAssignment(&validWitness),
+				test.WithInvalidAssignment(&invalidWitness),
+				test.WithCurves(snarkCurve(id)))
+		})
+	}
+}
+
+type scalarMulDefault struct {
+	curveID twistededwards.ID
+	P       tEd.Point
+	R       tEd.Point
+	S       frontend.Variable
+}
+
+func (circuit *scalarMulDefault) Define(api frontend.API) error {
+	res := ScalarMul(api, &circuit.P, circuit.S, circuit.curveID)
+	api.AssertIsEqual(res.X, circuit.R.X)
+	api.AssertIsEqual(res.Y, circuit.R.Y)
+
+	return nil
+}
+
+// TestScalarMul checks that ScalarMul picks the cheapest method of each
+// constraint system and computes [s]p.
+func TestScalarMul(t *testing.T) {
+	for _, id := range []twistededwards.ID{twistededwards.BLS12_381, twistededwards.BLS12_381_BANDERSNATCH} {
+		params := mustCurveParams(id)
+		p, r, s := scalarMulAssignments(t, id)
+		var r1csMethod frontend.Circuit = &scalarMulFakeGLV{curveID: id}
+		if id == twistededwards.BLS12_381_BANDERSNATCH {
+			r1csMethod = &scalarMulGLVAndFakeGLVLog{curveID: id}
+		}
+		for _, tc := range []struct {
+			builder  frontend.NewBuilder
+			expected frontend.Circuit
+		}{
+			{scs.NewBuilder, &scalarMulGeneric{curveID: id}},
+			{r1cs.NewBuilder, r1csMethod},
+		} {
+			ccs, err := frontend.Compile(params.Field, tc.builder, &scalarMulDefault{curveID: id})
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := frontend.Compile(params.Field, tc.builder, tc.expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ccs.GetNbConstraints() != expected.GetNbConstraints() {
+				t.Fatalf("%s: %d constraints, expected %d", curveNames[id], ccs.GetNbConstraints(), expected.GetNbConstraints())
+			}
+			w, err := frontend.NewWitness(&scalarMulDefault{
+				P: tEd.Point{X: p[0], Y: p[1]},
+				R: tEd.Point{X: r[0], Y: r[1]},
+				S: s,
+			}, params.Field)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ccs.IsSolved(w); err != nil {
+				t.Fatalf("%s: %v", curveNames[id], err)
+			}
+		}
+	}
+}
+
+type scalarMulGLV struct {
+	curveID twistededwards.ID
+	P       tEd.Point
+	R       tEd.Point
+	S       frontend.Variable
+}
+
+func (circuit *scalarMulGLV) Define(api frontend.API) error {
+	res := ScalarMulGLV(api, &circuit.P, circuit.S)
+	api.AssertIsEqual(res.X, circuit.R.X)
+	api.AssertIsEqual(res.Y, circuit.R.Y)
+
+	return nil
+}
+
+func TestScalarMulGLV(t *testing.T) {
+	assert := test.NewAssert(t)
+	var circuit, validWitness, invalidWitness scalarMulGLV
+	circuit.curveID = twistededwards.BLS12_381_BANDERSNATCH
+
+	// get curve params
+	params, err := tEd.GetCurveParams(twistededwards.BLS12_381_BANDERSNATCH)
+	assert.NoError(err)
+
+	// create witness
+	var p, r tbls12381_bandersnatch.PointAffine
+	s, _ := rand.Int(rand.Reader, params.Order)
+	p.X.SetBigInt(params.Base[0])
+	p.Y.SetBigInt(params.Base[1])
+	r.ScalarMultiplication(&p, s)
+
+	validWitness.P.X = p.X
+	validWitness.P.Y = p.Y
+	validWitness.R.X = r.X
+	validWitness.R.Y = r.Y
+	validWitness.S = s
+	invalidWitness.P.X = r.X
+	invalidWitness.P.Y = r.Y
+	invalidWitness.R.X = p.X
+	invalidWitness.R.Y = p.Y
+	invalidWitness.S = s
+
+	// check circuits.
+	assert.CheckCircuit(&circuit,
+		test.WithValidAssignment(&validWitness),
+		test.WithInvalidAssignment(&invalidWitness),
+		test.WithCurves(ecc.BLS12_381))
+
+}
+
+// TestScalarMulGLVMaliciousHints checks that a prover replacing the
+// decomposition hint cannot make the 2D GLV circuit accept a wrong result.
+func TestScalarMulGLVMaliciousHints(t *testing.T) {
+	id := twistededwards.BLS12_381_BANDERSNATCH
+	params := mustCurveParams(id)
+	p, r, s := scalarMulAssignments(t, id)
+	sHi := new(big.Int).Div(s, params.Cofactor)
+
+	// other has the same s mod h, so that only s' = ⌊s/h⌋ differs.
+	other, _ := rand.Int(rand.Reader, params.Order)
+	other.Sub(other, new(big.Int).Mod(other, params.Cofactor)).Add(other, new(big.Int).Mod(s, params.Cofactor))
+	otherHi := new(big.Int).Div(other, params.Cofactor)
+	var rOther [2]*big.Int
+	rOther[0], rOther[1] = new(big.Int), new(big.Int)
+	if err := scalarMulNative(id, p[0], p[1], other, rOther[0], rOther[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	withScalar := func(x *big.Int) solver.Hint {
+		return func(mod *big.Int, inputs, outputs []*big.Int) error {
+			in := append([]*big.Int{}, inputs...)
+			if in[0].Cmp(sHi) == 0 {
+				in[0] = x
+			}
+			return glvDecompose(mod, in, outputs)
+		}
+	}
+	flipSign := func(mod *big.Int, inputs, outputs []*big.Int) error {
+		if err := glvDecompose(mod, inputs, outputs); err != nil {
+			return err
+		}
+		outputs[3].Xor(outputs[3], big.NewInt(1))
+		return nil
+	}
+	override := func(by solver.Hint) solver.Option {
+		return solver.OverrideHint(solver.GetHintID(glvDecompose), by)
+	}
+
+	ccs, err := frontend.Compile(params.Field, scs.NewBuilder, &scalarMulGLV{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name  string
+		r     [2]*big.Int
+		opts  []solver.Option
+		valid bool
+	}{
+		{"honest", r, nil, true},
+		{"decomposition of another scalar", rOther, []solver.Option{override(withScalar(otherHi))}, false},
+		{"flipped sign", r, []solver.Option{override(flipSign)}, false},
+	} {
+		assignment := &scalarMulGLV{P: tEd.Point{X: p[0], Y: p[1]}, R: tEd.Point{X: tc.r[0], Y: tc.r[1]}, S: s}
+		w, err := frontend.NewWitness(assignment, params.Field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ccs.IsSolved(w, tc.opts...)
+		if tc.valid && err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !tc.valid && err == nil {
+			t.Fatalf("%s: the malicious hint was accepted", tc.name)
+		}
+	}
+}
+
 type scalarMulGLVAndFakeGLV struct {
 	curveID twistededwards.ID
 	P       tEd.Point
@@ -286,6 +603,479 @@ func TestScalarMulGLVAndFakeGLVLog(t *testing.T) {
 
 }
 
+// TestScalarMulGLVAndFakeGLVMaliciousHints checks that a prover replacing the
+// decomposition hints cannot make the 4D circuits accept a wrong result.
+func TestScalarMulGLVAndFakeGLVMaliciousHints(t *testing.T) {
+	params, err := tEd.GetCurveParams(twistededwards.BLS12_381_BANDERSNATCH)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var p, r, rOther tbls12381_bandersnatch.PointAffine
+	s, _ := rand.Int(rand.Reader, params.Order)
+	other, _ := rand.Int(rand.Reader, params.Order)
+	// the circuits decompose s' = ⌊s/h⌋, other has the same s mod h.
+	other.Sub(other, new(big.Int).Mod(other, params.Cofactor)).Add(other, new(big.Int).Mod(s, params.Cofactor))
+	sHi := new(big.Int).Div(s, params.Cofactor)
+	otherHi := new(big.Int).Div(other, params.Cofactor)
+	p.X.SetBigInt(params.Base[0])
+	p.Y.SetBigInt(params.Base[1])
+	r.ScalarMultiplication(&p, s)
+	rOther.ScalarMultiplication(&p, other)
+	lambda := mustCurveParams(twistededwards.BLS12_381_BANDERSNATCH).Lambda
+
+	// withScalar runs the hint h on the scalar x instead of the circuit's s'.
+	withScalar := func(h solver.Hint, idx int, x *big.Int) solver.Hint {
+		return func(mod *big.Int, inputs, outputs []*big.Int) error {
+			in := append([]*big.Int{}, inputs...)
+			if in[idx].Cmp(sHi) == 0 {
+				in[idx] = x
+			}
+			return h(mod, in, outputs)
+		}
+	}
+	tamper := func(f func(outputs []*big.Int) error) solver.Hint {
+		return func(mod *big.Int, inputs, outputs []*big.Int) error {
+			if err := halfGCDZZ2Combined(mod, inputs, outputs); err != nil {
+				return err
+			}
+			return f(outputs)
+		}
+	}
+	otherScalar := tamper(func(outputs []*big.Int) error {
+		res := newBigInts(zz2NbOutputs)
+		if err := halfGCDZZ2Combined(ecc.BLS12_381.ScalarField(), []*big.Int{otherHi, lambda}, res); err != nil {
+			return err
+		}
+		for i := range outputs {
+			outputs[i].Set(res[i])
+		}
+		return nil
+	})
+	zero := tamper(func(outputs []*big.Int) error {
+		for i := range outputs {
+			outputs[i].SetUint64(0)
+		}
+		return nil
+	})
+	flipFirst := tamper(func(outputs []*big.Int) error {
+		outputs[zz2SignsOffset].Xor(outputs[zz2SignsOffset], big.NewInt(1))
+		return nil
+	})
+	override := func(h, by solver.Hint) solver.Option {
+		return solver.OverrideHint(solver.GetHintID(h), by)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		r     *tbls12381_bandersnatch.PointAffine
+		opts  []solver.Option
+		valid bool
+	}{
+		{"honest", &r, nil, true},
+		{
+			// the MSM equation holds for [other]P: only the emulated
+			// relation on s catches it.
+			"decomposition of another scalar", &rOther, []solver.Option{
+				override(halfGCDZZ2Combined, otherScalar),
+				override(scalarMulHint, withScalar(scalarMulHint, 2, otherHi)),
+			}, false,
+		},
+		{
+			"zero decomposition", &rOther, []solver.Option{
+				override(halfGCDZZ2Combined, zero),
+				override(scalarMulHint, withScalar(scalarMulHint, 2, otherHi)),
+			}, false,
+		},
+		{
+			"flipped sign", &r, []solver.Option{
+				override(halfGCDZZ2Combined, flipFirst),
+			}, false,
+		},
+	} {
+		for _, c := range []frontend.Circuit{&scalarMulGLVAndFakeGLV{}, &scalarMulGLVAndFakeGLVLog{}, &scalarMulGLVAndFakeGLVSigned{}} {
+			ccs, err := frontend.Compile(ecc.BLS12_381.ScalarField(), scs.NewBuilder, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var assignment frontend.Circuit
+			switch c.(type) {
+			case *scalarMulGLVAndFakeGLV:
+				assignment = &scalarMulGLVAndFakeGLV{P: tEd.Point{X: p.X, Y: p.Y}, R: tEd.Point{X: tc.r.X, Y: tc.r.Y}, S: s}
+			case *scalarMulGLVAndFakeGLVSigned:
+				assignment = &scalarMulGLVAndFakeGLVSigned{P: tEd.Point{X: p.X, Y: p.Y}, R: tEd.Point{X: tc.r.X, Y: tc.r.Y}, S: s}
+			default:
+				assignment = &scalarMulGLVAndFakeGLVLog{P: tEd.Point{X: p.X, Y: p.Y}, R: tEd.Point{X: tc.r.X, Y: tc.r.Y}, S: s}
+			}
+			w, err := frontend.NewWitness(assignment, ecc.BLS12_381.ScalarField())
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = ccs.IsSolved(w, tc.opts...)
+			if tc.valid && err != nil {
+				t.Fatalf("%s (%T): %v", tc.name, c, err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("%s (%T): the malicious hints were accepted", tc.name, c)
+			}
+		}
+	}
+}
+
+// addNative returns p + q on the twisted Edwards curve id.
+func addNative(id twistededwards.ID, p, q [2]*big.Int) [2]*big.Int {
+	params, err := tEd.GetCurveParams(id)
+	if err != nil {
+		panic(err)
+	}
+	field := mustCurveParams(id).Field
+	var x1y2, y1x2, x1x2, y1y2, dxy, num, den big.Int
+	x1y2.Mul(p[0], q[1])
+	y1x2.Mul(p[1], q[0])
+	x1x2.Mul(p[0], q[0])
+	y1y2.Mul(p[1], q[1])
+	dxy.Mul(&x1x2, &y1y2).Mul(&dxy, params.D)
+
+	// x3 = (x1y2 + y1x2) / (1 + d x1x2y1y2)
+	// y3 = (y1y2 - a x1x2) / (1 - d x1x2y1y2)
+	var res [2]*big.Int
+	num.Add(&x1y2, &y1x2)
+	den.Add(big.NewInt(1), &dxy).ModInverse(den.Mod(&den, field), field)
+	res[0] = new(big.Int).Mul(&num, &den)
+	res[0].Mod(res[0], field)
+	num.Mul(params.A, &x1x2).Sub(&y1y2, &num)
+	den.Sub(big.NewInt(1), &dxy).ModInverse(den.Mod(&den, field), field)
+	res[1] = new(big.Int).Mul(&num, &den)
+	res[1].Mod(res[1], field)
+	return res
+}
+
+// isOnCurveNative reports whether p is an affine point of the curve id.
+func isOnCurveNative(id twistededwards.ID, p [2]*big.Int) bool {
+	params, err := tEd.GetCurveParams(id)
+	if err != nil {
+		panic(err)
+	}
+	field := mustCurveParams(id).Field
+	// a x² + y² == 1 + d x² y²
+	var xx, yy, lhs, rhs big.Int
+	xx.Mul(p[0], p[0])
+	yy.Mul(p[1], p[1])
+	lhs.Mul(params.A, &xx).Add(&lhs, &yy).Mod(&lhs, field)
+	rhs.Mul(params.D, &xx).Mul(&rhs, &yy).Add(&rhs, big.NewInt(1)).Mod(&rhs, field)
+	return lhs.Cmp(&rhs) == 0
+}
+
+// curvePoints returns the affine points of the curve id with y ≥ 2 and in
+// increasing order of y. Most of them have a small order component.
+func curvePoints(id twistededwards.ID, n int) [][2]*big.Int {
+	params, err := tEd.GetCurveParams(id)
+	if err != nil {
+		panic(err)
+	}
+	field := mustCurveParams(id).Field
+	var res [][2]*big.Int
+	for y := int64(2); len(res) < n; y++ {
+		// x² = (1 - y²) / (a - d y²)
+		var yy, num, den big.Int
+		yy.SetInt64(y * y)
+		num.Sub(big.NewInt(1), &yy).Mod(&num, field)
+		den.Mul(params.D, &yy).Sub(params.A, &den).Mod(&den, field)
+		if den.ModInverse(&den, field) == nil {
+			continue
+		}
+		num.Mul(&num, &den).Mod(&num, field)
+		x := new(big.Int).ModSqrt(&num, field)
+		if x == nil {
+			continue
+		}
+		res = append(res, [2]*big.Int{x, big.NewInt(y)})
+	}
+	return res
+}
+
+// smallOrderPoint returns an affine point of the largest order dividing h, the
+// cofactor of the curve id. It is (0,-1), of order 2, when the other points of
+// small order are at infinity, as on Bandersnatch.
+func smallOrderPoint(id twistededwards.ID) [2]*big.Int {
+	params, err := tEd.GetCurveParams(id)
+	if err != nil {
+		panic(err)
+	}
+	field := mustCurveParams(id).Field
+	halfCofactor := new(big.Int).Rsh(params.Cofactor, 1)
+	for _, p := range curvePoints(id, 16) {
+		// [r]p has order dividing h
+		var t [2]*big.Int
+		t[0], t[1] = new(big.Int), new(big.Int)
+		if err := scalarMulNative(id, p[0], p[1], params.Order, t[0], t[1]); err != nil {
+			panic(err)
+		}
+		if !isOnCurveNative(id, t) {
+			continue
+		}
+		var tx, ty big.Int
+		if err := scalarMulNative(id, t[0], t[1], halfCofactor, &tx, &ty); err != nil {
+			panic(err)
+		}
+		if tx.Sign() != 0 || ty.Cmp(big.NewInt(1)) != 0 {
+			return t
+		}
+	}
+	return [2]*big.Int{big.NewInt(0), new(big.Int).Sub(field, big.NewInt(1))}
+}
+
+// TestScalarMulEdgeCases checks the fake GLV circuits on the scalars 0, 1,
+// r-1, r and 2^253-1 and on the identity, a point T of small order and points
+// with a small order component. The results shifted by T must be rejected, as
+// well as any result when [s]P is not affine (on Bandersnatch).
+func TestScalarMulEdgeCases(t *testing.T) {
+	type testCase struct {
+		name    string
+		id      twistededwards.ID
+		circuit func(p, r tEd.Point, s frontend.Variable) frontend.Circuit
+	}
+	var cases []testCase
+	for _, id := range curveIDs {
+		id := id
+		cases = append(cases, testCase{"FakeGLV/" + curveNames[id], id, func(p, r tEd.Point, s frontend.Variable) frontend.Circuit {
+			return &scalarMulFakeGLV{curveID: id, P: p, R: r, S: s}
+		}})
+		cases = append(cases, testCase{"FakeGLVSigned/" + curveNames[id], id, func(p, r tEd.Point, s frontend.Variable) frontend.Circuit {
+			return &scalarMulFakeGLVSigned{curveID: id, P: p, R: r, S: s}
+		}})
+	}
+	cases = append(cases,
+		testCase{"GLV", twistededwards.BLS12_381_BANDERSNATCH, func(p, r tEd.Point, s frontend.Variable) frontend.Circuit {
+			return &scalarMulGLV{P: p, R: r, S: s}
+		}},
+		testCase{"GLVAndFakeGLV", twistededwards.BLS12_381_BANDERSNATCH, func(p, r tEd.Point, s frontend.Variable) frontend.Circuit {
+			return &scalarMulGLVAndFakeGLV{P: p, R: r, S: s}
+		}},
+		testCase{"GLVAndFakeGLVLog", twistededwards.BLS12_381_BANDERSNATCH, func(p, r tEd.Point, s frontend.Variable) frontend.Circuit {
+			return &scalarMulGLVAndFakeGLVLog{P: p, R: r, S: s}
+		}},
+		testCase{"GLVAndFakeGLVSigned", twistededwards.BLS12_381_BANDERSNATCH, func(p, r tEd.Point, s frontend.Variable) frontend.Circuit {
+			return &scalarMulGLVAndFakeGLVSigned{P: p, R: r, S: s}
+		}},
+	)
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			params, err := tEd.GetCurveParams(tc.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			field := mustCurveParams(tc.id).Field
+			ccs, err := frontend.Compile(field, scs.NewBuilder, tc.circuit(tEd.Point{}, tEd.Point{}, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			g := [2]*big.Int{params.Base[0], params.Base[1]}
+			identity := [2]*big.Int{big.NewInt(0), big.NewInt(1)}
+			torsion := smallOrderPoint(tc.id)
+			points := map[string][2]*big.Int{
+				"G":     g,
+				"(0,1)": identity,
+				"T":     torsion,
+				"G+T":   addNative(tc.id, g, torsion),
+			}
+			for i, p := range curvePoints(tc.id, 2) {
+				points[fmt.Sprintf("P%d", i)] = p
+			}
+			scalars := edgeScalars(params.Order)
+			s, _ := rand.Int(rand.Reader, params.Order)
+			scalars = append(scalars, s)
+
+			for name, p := range points {
+				for _, s := range scalars {
+					var r [2]*big.Int
+					r[0], r[1] = new(big.Int), new(big.Int)
+					if err := scalarMulNative(tc.id, p[0], p[1], new(big.Int).Mod(s, field), r[0], r[1]); err != nil {
+						t.Fatal(err)
+					}
+					for _, expected := range []struct {
+						r     [2]*big.Int
+						valid bool
+					}{
+						{r, isOnCurveNative(tc.id, r)},
+						{addNative(tc.id, r, torsion), false},
+					} {
+						assignment := tc.circuit(
+							tEd.Point{X: p[0], Y: p[1]},
+							tEd.Point{X: expected.r[0], Y: expected.r[1]},
+							s,
+						)
+						w, err := frontend.NewWitness(assignment, field)
+						if err != nil {
+							t.Fatal(err)
+						}
+						err = ccs.IsSolved(w)
+						if expected.valid && err != nil {
+							t.Fatalf("[%s]%s: %v", s, name, err)
+						}
+						if !expected.valid && err == nil {
+							t.Fatalf("[%s]%s: a wrong result was accepted", s, name)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+// evenDecomposition returns a vector (s1, s2) of the lattice
+// {(x, y) : x + s*y = 0 mod r} with an even s2 ≠ 0 and |s1|, |s2| < 2^n, or
+// nils if none is found among small combinations of the reduced basis.
+func evenDecomposition(s, r *big.Int, n int) [2]*big.Int {
+	var basis ecc.Lattice
+	ecc.PrecomputeLattice(r, new(big.Int).Mod(s, r), &basis)
+	for _, c := range [][2]int64{{1, 0}, {0, 1}, {1, 1}, {1, -1}} {
+		var v [2]*big.Int
+		for i := range v {
+			v[i] = new(big.Int).Mul(&basis.V1[i], big.NewInt(c[0]))
+			v[i].Add(v[i], new(big.Int).Mul(&basis.V2[i], big.NewInt(c[1])))
+		}
+		if v[1].Sign() != 0 && v[1].Bit(0) == 0 && v[0].CmpAbs(new(big.Int).Lsh(big.NewInt(1), uint(n))) < 0 && v[1].CmpAbs(new(big.Int).Lsh(big.NewInt(1), uint(n))) < 0 {
+			return v
+		}
+	}
+	return [2]*big.Int{}
+}
+
+// TestScalarMulFakeGLVMaliciousHints checks that a prover replacing the hints
+// cannot make the 2D fake GLV circuit accept a wrong result.
+func TestScalarMulFakeGLVMaliciousHints(t *testing.T) {
+	for _, id := range curveIDs {
+		id := id
+		t.Run(curveNames[id], func(t *testing.T) {
+			t.Parallel()
+			params := mustCurveParams(id)
+			p, r, s := scalarMulAssignments(t, id)
+			n := params.Order.BitLen()/2 + 1
+			// a scalar with a valid decomposition of s' = ⌊s/h⌋ with an even s2,
+			// that cannot tell Q from Q + (0,-1).
+			even := evenDecomposition(new(big.Int).Div(s, params.Cofactor), params.Order, n)
+			for even[0] == nil {
+				p, r, s = scalarMulAssignments(t, id)
+				even = evenDecomposition(new(big.Int).Div(s, params.Cofactor), params.Order, n)
+			}
+			t2 := [2]*big.Int{big.NewInt(0), new(big.Int).Sub(params.Field, big.NewInt(1))}
+
+			// other has the same s mod h, so that only the decomposed part
+			// s' = ⌊s/h⌋ differs.
+			other, _ := rand.Int(rand.Reader, params.Order)
+			other.Sub(other, new(big.Int).Mod(other, params.Cofactor)).Add(other, new(big.Int).Mod(s, params.Cofactor))
+			sHi := new(big.Int).Div(s, params.Cofactor)
+			otherHi := new(big.Int).Div(other, params.Cofactor)
+			var rOther [2]*big.Int
+			rOther[0], rOther[1] = new(big.Int), new(big.Int)
+			if err := scalarMulNative(id, p[0], p[1], other, rOther[0], rOther[1]); err != nil {
+				t.Fatal(err)
+			}
+
+			withScalar := func(x *big.Int) solver.Hint {
+				return func(mod *big.Int, inputs, outputs []*big.Int) error {
+					in := append([]*big.Int{}, inputs...)
+					if in[0].Cmp(sHi) == 0 {
+						in[0] = x
+					}
+					return halfGCD(mod, in, outputs)
+				}
+			}
+			zero := func(mod *big.Int, inputs, outputs []*big.Int) error {
+				for i := range outputs {
+					outputs[i].SetUint64(0)
+				}
+				return nil
+			}
+			evenHint := func(mod *big.Int, inputs, outputs []*big.Int) error {
+				for i, c := range even {
+					outputs[i].Abs(c)
+					outputs[2+i].SetUint64(0)
+					if c.Sign() == -1 {
+						outputs[2+i].SetUint64(1)
+					}
+				}
+				return nil
+			}
+			// the claimed [s']([h]P) is shifted by (0,-1)
+			shifted := func(mod *big.Int, inputs, outputs []*big.Int) error {
+				if err := scalarMulHint(mod, inputs, outputs); err != nil {
+					return err
+				}
+				res := addNative(id, [2]*big.Int{outputs[0], outputs[1]}, t2)
+				outputs[0].Set(res[0])
+				outputs[1].Set(res[1])
+				return nil
+			}
+			otherResult := func(mod *big.Int, inputs, outputs []*big.Int) error {
+				in := append([]*big.Int{}, inputs...)
+				if in[2].Cmp(sHi) == 0 {
+					in[2] = otherHi
+				}
+				return scalarMulHint(mod, in, outputs)
+			}
+			override := func(h, by solver.Hint) solver.Option {
+				return solver.OverrideHint(solver.GetHintID(h), by)
+			}
+
+			for _, signed := range []bool{false, true} {
+				var circuit frontend.Circuit = &scalarMulFakeGLV{curveID: id}
+				if signed {
+					circuit = &scalarMulFakeGLVSigned{curveID: id}
+				}
+				ccs, err := frontend.Compile(params.Field, scs.NewBuilder, circuit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, tc := range []struct {
+					name  string
+					r     [2]*big.Int
+					opts  []solver.Option
+					valid bool
+				}{
+					{"honest", r, nil, true},
+					{"decomposition of another scalar", rOther, []solver.Option{
+						override(halfGCD, withScalar(otherHi)),
+						override(scalarMulHint, otherResult),
+					}, false},
+					{"zero decomposition", rOther, []solver.Option{
+						override(halfGCD, zero),
+						override(scalarMulHint, otherResult),
+					}, false},
+					{"even decomposition", r, []solver.Option{
+						override(halfGCD, evenHint),
+					}, true},
+					{"result shifted by (0,-1)", addNative(id, r, t2), []solver.Option{
+						override(halfGCD, evenHint),
+						override(scalarMulHint, shifted),
+					}, false},
+				} {
+					var assignment frontend.Circuit = &scalarMulFakeGLV{P: tEd.Point{X: p[0], Y: p[1]}, R: tEd.Point{X: tc.r[0], Y: tc.r[1]}, S: s}
+					if signed {
+						assignment = &scalarMulFakeGLVSigned{P: tEd.Point{X: p[0], Y: p[1]}, R: tEd.Point{X: tc.r[0], Y: tc.r[1]}, S: s}
+					}
+					w, err := frontend.NewWitness(assignment, params.Field)
+					if err != nil {
+						t.Fatal(err)
+					}
+					err = ccs.IsSolved(w, tc.opts...)
+					if tc.valid && err != nil {
+						t.Fatalf("%s (signed: %t): %v", tc.name, signed, err)
+					}
+					if !tc.valid && err == nil {
+						t.Fatalf("%s (signed: %t): the malicious hints were accepted", tc.name, signed)
+					}
+				}
+			}
+		})
+	}
+}
+
 // bench
 func BenchmarkScalarMulGenericJubjubSCS(b *testing.B) {
 	c := scalarMulGeneric{curveID: twistededwards.BLS12_381}
@@ -303,6 +1093,25 @@ func BenchmarkScalarMulGenericJubjubR1CS(b *testing.B) {
 	fmt.Println("Jubjub generic (r1cs): ", p.NbConstraints())
 }
 
+// BenchmarkScalarMulGenericWindowed prints the number of constraints of
+// ScalarMulGenericWindowed on Jubjub and Bandersnatch for every window size.
+func BenchmarkScalarMulGenericWindowed(b *testing.B) {
+	for _, id := range []twistededwards.ID{twistededwards.BLS12_381, twistededwards.BLS12_381_BANDERSNATCH} {
+		for w := 2; w <= 6; w++ {
+			c := scalarMulGenericWindowed{curveID: id, w: w}
+			p := profile.Start()
+			_, _ = frontend.Compile(ecc.BLS12_381.ScalarField(), scs.NewBuilder, &c)
+			p.Stop()
+			fmt.Printf("%s generic w=%d (scs): %d\n", curveNames[id], w, p.NbConstraints())
+
+			p = profile.Start()
+			_, _ = frontend.Compile(ecc.BLS12_381.ScalarField(), r1cs.NewBuilder, &c)
+			p.Stop()
+			fmt.Printf("%s generic w=%d (r1cs): %d\n", curveNames[id], w, p.NbConstraints())
+		}
+	}
+}
+
 func BenchmarkScalarMulFakeGLVJubjubSCS(b *testing.B) {
 	c := scalarMulFakeGLV{curveID: twistededwards.BLS12_381}
 	p := profile.Start()
@@ -320,7 +1129,7 @@ func BenchmarkScalarMulFakeGLVJubjubR1CS(b *testing.B) {
 }
 
 func BenchmarkScalarMulGenericBandersnatchSCS(b *testing.B) {
-	c := scalarMulGeneric{curveID: twistededwards.BLS12_381}
+	c := scalarMulGeneric{curveID: twistededwards.BLS12_381_BANDERSNATCH}
 	p := profile.Start()
 	_, _ = frontend.Compile(ecc.BLS12_381.ScalarField(), scs.NewBuilder, &c)
 	p.Stop()
@@ -351,6 +1160,22 @@ func BenchmarkScalarMulFakeGLVBandersnatchR1CS(b *testing.B) {
 	fmt.Println("Bandersnatch 2D hinted (r1cs): ", p.NbConstraints())
 }
 
+func BenchmarkScalarMulGLVBandersnatchSCS(b *testing.B) {
+	c := scalarMulGLV{curveID: twistededwards.BLS12_381_BANDERSNATCH}
+	p := profile.Start()
+	_, _ = frontend.Compile(ecc.BLS12_381.ScalarField(), scs.NewBuilder, &c)
+	p.Stop()
+	fmt.Println("Bandersnatch 2D GLV (scs): ", p.NbConstraints())
+}
+
+func BenchmarkScalarMulGLVBandersnatchR1CS(b *testing.B) {
+	c := scalarMulGLV{curveID: twistededwards.BLS12_381_BANDERSNATCH}
+	p := profile.Start()
+	_, _ = frontend.Compile(ecc.BLS12_381.ScalarField(), r1cs.NewBuilder, &c)
+	p.Stop()
+	fmt.Println("Bandersnatch 2D GLV (r1cs): ", p.NbConstraints())
+}
+
 func BenchmarkScalarMulGLVAndFakeGLVBandersnatchSCS(b *testing.B) {
 	c := scalarMulGLVAndFakeGLV{curveID: twistededwards.BLS12_381_BANDERSNATCH}
 	p := profile.Start()
@@ -382,3 +1207,28 @@ func BenchmarkScalarMulGLVAndFakeGLVLogupBandersnatchR1CS(b *testing.B) {
 	p.Stop()
 	fmt.Println("Bandersnatch 4D hinted with logup (r1cs): ", p.NbConstraints())
 }
+
+// BenchmarkScalarMulAllCurves prints the number of constraints of the generic
+// and 2D hinted GLV scalar multiplications on every twisted Edwards curve.
+func BenchmarkScalarMulAllCurves(b *testing.B) {
+	for _, id := range curveIDs {
+		field := snarkCurve(id).ScalarField()
+		for _, c := range []struct {
+			name    string
+			circuit frontend.Circuit
+		}{
+			{"generic", &scalarMulGeneric{curveID: id}},
+			{"2D hinted GLV", &scalarMulFakeGLV{curveID: id}},
+		} {
+			p := profile.Start()
+			_, _ = frontend.Compile(field, scs.NewBuilder, c.circuit)
+			p.Stop()
+			fmt.Printf("%s %s (scs): %d\n", curveNames[id], c.name, p.NbConstraints())
+
+			p = profile.Start()
+			_, _ = frontend.Compile(field, r1cs.NewBuilder, c.circuit)
+			p.Stop()
+			fmt.Printf("%s %s (r1cs): %d\n", curveNames[id], c.name, p.NbConstraints())
+		}
+	}
+}
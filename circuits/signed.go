@@ -0,0 +1,136 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	tEd "github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+)
+
+// ScalarMulFakeGLVSigned is ScalarMulFakeGLV with the check
+// [|s1|](±P) + [|s2|](±q') = (0,1) on signed digits, see signedDigitsCheck:
+// the table holds the two points ±P + ±q' up to sign instead of the four
+// subset sums of ±P and ±q', and no addition of the identity is needed.
+func ScalarMulFakeGLVSigned(api frontend.API, p *tEd.Point, scalar frontend.Variable, id twistededwards.ID) *tEd.Point {
+	// get edwards curve curve
+	curve, err := tEd.NewEdCurve(api, id)
+	if err != nil {
+		return nil
+	}
+	params, err := getCurveParams(id)
+	if err != nil {
+		return nil
+	}
+
+	low, q, pts, bits := fakeGLVDecompose(api, curve, p, scalar, params)
+	signedDigitsCheck(api, curve, pts[:], bits[:])
+
+	res := curve.Add(low, q)
+	return &res
+}
+
+// ScalarMulGLVAndFakeGLVSigned is ScalarMulGLVAndFakeGLVLog with the check
+// [|u1|](±P) + [|u2|](±φ(P)) + [|v1|](±q') + [|v2|](±φ(q')) = (0,1) on signed
+// digits, see signedDigitsCheck: the logup table holds the 8 points
+// ±P ± φ(P) ± q' ± φ(q') up to sign instead of the 16 subset sums.
+//
+// Unlike in the 2D loop, the recoding does not pay off here: the logup index
+// is already a free linear combination of the bits, while the sign of each
+// digit relative to the first one costs an XOR per point and the negation a
+// select per iteration, so that it is larger than ScalarMulGLVAndFakeGLVLog
+// (see BenchmarkScalarMulSigned). It is kept for comparison.
+func ScalarMulGLVAndFakeGLVSigned(api frontend.API, p *tEd.Point, scalar frontend.Variable) *tEd.Point {
+	// get edwards curve curve
+	curve, err := tEd.NewEdCurve(api, twistededwards.BLS12_381_BANDERSNATCH)
+	if err != nil {
+		return nil
+	}
+	params := mustCurveParams(twistededwards.BLS12_381_BANDERSNATCH)
+
+	low, q, pts, bits := glvAndFakeGLVDecompose(api, curve, p, scalar, params)
+	signedDigitsCheck(api, curve, pts[:], bits[:])
+
+	res := curve.Add(low, q)
+	return &res
+}
+
+// signedDigitsCheck checks that Σ [k_j]pts[j] = (0,1) for the integers k_j
+// with little-endian bits bits[j], all of the same length n, using the GLV-SAC
+// recoding of Faz-Hernández, Longa and Sánchez.
+//
+// Each k_j is made odd as k_j + e_j with e_j = 1 - bits[j][0], and the odd
+// integer k_j + e_j < 2^n is written with the digits ±1 as
+//
+//	k_j + e_j = 2^(n-1) + Σ_{i<n-1} (2*bits[j][i+1] - 1) * 2^i,
+//
+// so that the recoding needs no hint nor range check. The digits of the same
+// weight are d * (pts[0] ± pts[1] ± ...) where d is the digit of k_0, so that
+// the table holds only the 2^(m-1) sums pts[0] ± pts[1] ± ... of the m points
+// and the negation by d is free on twisted Edwards curves. The e_j * pts[j]
+// are subtracted at the end.
+//
+// The points must be in the prime subgroup, where the incomplete additions of
+// Bandersnatch are safe.
+func signedDigitsCheck(api frontend.API, curve tEd.Curve, pts []tEd.Point, bits [][]frontend.Variable) {
+	m := len(pts)
+	n := len(bits[0])
+
+	// t[k] = pts[0] + Σ_{j≥1} ±pts[j], with +pts[j] if bit j-1 of k is set
+	t := []tEd.Point{pts[0]}
+	for j := 1; j < m; j++ {
+		neg := tEd.Point{X: api.Neg(pts[j].X), Y: pts[j].Y}
+		next := make([]tEd.Point, 2*len(t))
+		for k := range t {
+			next[k] = curve.Add(t[k], neg)
+			next[k+len(t)] = curve.Add(t[k], pts[j])
+		}
+		t = next
+	}
+
+	// the index of the digits of weight 2^i, i < n-1, and the digit of k_0
+	var lookup func(i int) tEd.Point
+	if len(t) == 2 {
+		lookup = func(i int) tEd.Point {
+			same := api.Sub(1, api.Xor(bits[0][i+1], bits[1][i+1]))
+			return tEd.Point{
+				X: api.Select(same, t[1].X, t[0].X),
+				Y: api.Select(same, t[1].Y, t[0].Y),
+			}
+		}
+	} else {
+		tblX := logderivlookup.New(api)
+		tblY := logderivlookup.New(api)
+		for k := range t {
+			tblX.Insert(t[k].X)
+			tblY.Insert(t[k].Y)
+		}
+		lookup = func(i int) tEd.Point {
+			var ind frontend.Variable = 0
+			for j := m - 1; j >= 1; j-- {
+				same := api.Sub(1, api.Xor(bits[0][i+1], bits[j][i+1]))
+				ind = api.Add(api.Mul(ind, 2), same)
+			}
+			return tEd.Point{X: tblX.Lookup(ind)[0], Y: tblY.Lookup(ind)[0]}
+		}
+	}
+
+	// the most significant digits are all 1
+	res := t[len(t)-1]
+	for i := n - 2; i >= 0; i-- {
+		res = curve.Double(res)
+		tmp := lookup(i)
+		tmp.X = api.Select(bits[0][i+1], tmp.X, api.Neg(tmp.X))
+		res = curve.Add(res, tmp)
+	}
+
+	// res = Σ [k_j + e_j]pts[j]
+	for j := range pts {
+		res = curve.Add(res, tEd.Point{
+			X: api.Select(bits[j][0], 0, api.Neg(pts[j].X)),
+			Y: api.Select(bits[j][0], 1, pts[j].Y),
+		})
+	}
+
+	api.AssertIsEqual(res.X, 0)
+	api.AssertIsEqual(res.Y, 1)
+}
@@ -0,0 +1,161 @@
+package circuits
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/profile"
+	tEd "github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/test"
+)
+
+type scalarMulFakeGLVSigned struct {
+	curveID twistededwards.ID
+	P       tEd.Point
+	R       tEd.Point
+	S       frontend.Variable
+}
+
+func (circuit *scalarMulFakeGLVSigned) Define(api frontend.API) error {
+	res := ScalarMulFakeGLVSigned(api, &circuit.P, circuit.S, circuit.curveID)
+	api.AssertIsEqual(res.X, circuit.R.X)
+	api.AssertIsEqual(res.Y, circuit.R.Y)
+
+	return nil
+}
+
+type scalarMulGLVAndFakeGLVSigned struct {
+	P tEd.Point
+	R tEd.Point
+	S frontend.Variable
+}
+
+func (circuit *scalarMulGLVAndFakeGLVSigned) Define(api frontend.API) error {
+	res := ScalarMulGLVAndFakeGLVSigned(api, &circuit.P, circuit.S)
+	api.AssertIsEqual(res.X, circuit.R.X)
+	api.AssertIsEqual(res.Y, circuit.R.Y)
+
+	return nil
+}
+
+type signedDigits struct {
+	curveID twistededwards.ID
+	P       [2]tEd.Point
+	K       [2][]frontend.Variable
+}
+
+func (circuit *signedDigits) Define(api frontend.API) error {
+	curve, err := tEd.NewEdCurve(api, circuit.curveID)
+	if err != nil {
+		return err
+	}
+	for j := range circuit.K {
+		for _, b := range circuit.K[j] {
+			api.AssertIsBoolean(b)
+		}
+	}
+	signedDigitsCheck(api, curve, circuit.P[:], circuit.K[:])
+	return nil
+}
+
+// TestSignedDigitsCheck checks signedDigitsCheck on [k1]G + [k2]Q = (0,1) for
+// Q = [-k1/k2 mod r]G, for every parity of k1 and k2 and for the
+// decomposition (0, 1) of s = 0 mod r given by the halfGCD hint, where Q is
+// (0,1). Replacing Q by another point must be rejected.
+func TestSignedDigitsCheck(t *testing.T) {
+	for _, id := range []twistededwards.ID{twistededwards.BLS12_381, twistededwards.BLS12_381_BANDERSNATCH} {
+		params, err := tEd.GetCurveParams(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := params.Order
+		n := r.BitLen()/2 + 1
+
+		type testCase struct {
+			name string
+			k    [2]*big.Int
+		}
+		zero := newBigInts(4)
+		if err := halfGCD(nil, []*big.Int{r, r}, zero); err != nil {
+			t.Fatal(err)
+		}
+		cases := []testCase{{"s = 0 mod r", [2]*big.Int{zero[0], zero[1]}}}
+		bound := new(big.Int).Lsh(big.NewInt(1), uint(n))
+		for _, parity := range [][2]uint{{0, 0}, {0, 1}, {1, 0}, {1, 1}} {
+			var k [2]*big.Int
+			for j := range k {
+				k[j], _ = rand.Int(rand.Reader, bound)
+				k[j].SetBit(k[j], 0, parity[j])
+			}
+			cases = append(cases, testCase{fmt.Sprintf("k1 mod 2 = %d, k2 mod 2 = %d", parity[0], parity[1]), k})
+		}
+
+		for _, tc := range cases {
+			// e = -k1/k2 mod r
+			e := new(big.Int).ModInverse(tc.k[1], r)
+			e.Mul(e, tc.k[0]).Neg(e).Mod(e, r)
+			q := [2]*big.Int{new(big.Int), new(big.Int)}
+			wrong := [2]*big.Int{new(big.Int), new(big.Int)}
+			if err := scalarMulNative(id, params.Base[0], params.Base[1], e, q[0], q[1]); err != nil {
+				t.Fatal(err)
+			}
+			if err := scalarMulNative(id, params.Base[0], params.Base[1], e.Add(e, big.NewInt(1)), wrong[0], wrong[1]); err != nil {
+				t.Fatal(err)
+			}
+
+			circuit := signedDigits{curveID: id}
+			assignment := signedDigits{P: [2]tEd.Point{
+				{X: params.Base[0], Y: params.Base[1]},
+				{X: q[0], Y: q[1]},
+			}}
+			for j := range circuit.K {
+				circuit.K[j] = make([]frontend.Variable, n)
+				assignment.K[j] = make([]frontend.Variable, n)
+				for i := range assignment.K[j] {
+					assignment.K[j][i] = tc.k[j].Bit(i)
+				}
+			}
+			field := snarkCurve(id).ScalarField()
+			if err := test.IsSolved(&circuit, &assignment, field); err != nil {
+				t.Fatalf("%s, %s: %v", curveNames[id], tc.name, err)
+			}
+			assignment.P[1] = tEd.Point{X: wrong[0], Y: wrong[1]}
+			if err := test.IsSolved(&circuit, &assignment, field); err == nil {
+				t.Fatalf("%s, %s: a wrong point was accepted", curveNames[id], tc.name)
+			}
+		}
+	}
+}
+
+// BenchmarkScalarMulSigned prints the number of constraints of the fake GLV
+// scalar multiplications with signed digits next to the ones with bits.
+func BenchmarkScalarMulSigned(b *testing.B) {
+	for _, c := range []struct {
+		name    string
+		circuit frontend.Circuit
+	}{
+		{"Jubjub 2D fake GLV", &scalarMulFakeGLV{curveID: twistededwards.BLS12_381}},
+		{"Jubjub 2D fake GLV signed", &scalarMulFakeGLVSigned{curveID: twistededwards.BLS12_381}},
+		{"Bandersnatch 2D fake GLV", &scalarMulFakeGLV{curveID: twistededwards.BLS12_381_BANDERSNATCH}},
+		{"Bandersnatch 2D fake GLV signed", &scalarMulFakeGLVSigned{curveID: twistededwards.BLS12_381_BANDERSNATCH}},
+		{"Bandersnatch 4D fake GLV with logup", &scalarMulGLVAndFakeGLVLog{}},
+		{"Bandersnatch 4D fake GLV signed", &scalarMulGLVAndFakeGLVSigned{}},
+	} {
+		p := profile.Start()
+		_, _ = frontend.Compile(ecc.BLS12_381.ScalarField(), scs.NewBuilder, c.circuit)
+		p.Stop()
+		fmt.Printf("%s (scs): %d\n", c.name, p.NbConstraints())
+
+		p = profile.Start()
+		_, _ = frontend.Compile(ecc.BLS12_381.ScalarField(), r1cs.NewBuilder, c.circuit)
+		p.Stop()
+		fmt.Printf("%s (r1cs): %d\n", c.name, p.NbConstraints())
+	}
+}
@@ -0,0 +1,98 @@
+// Command hintdebug runs a hint of the circuits package on the given inputs
+// and checks its outputs against the native reference of the hintdebug
+// package.
+//
+// Usage:
+//
+//	hintdebug [-field curve] [-outputs n] [-run] hint input...
+//	hintdebug -list
+//
+// The inputs are integers in any base accepted by big.Int.SetString with base
+// 0, e.g. 42, 0x2a or 0b101010. The SNARK field is the scalar field of the
+// given curve, bls12_381 by default. For example, to decompose a scalar on
+// Bandersnatch:
+//
+//	hintdebug halfGCDZZ2Combined 0x2a 8913659658109529928382530854484400854125314752504019737736543920008458395397
+//
+// The exit status is 1 if the hint fails or its outputs do not pass the
+// reference.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/yelhousni/jubjub-vs-bandersnatch/circuits/hintdebug"
+)
+
+func main() {
+	list := flag.Bool("list", false, "list the hints with a reference implementation")
+	curve := flag.String("field", "bls12_381", "curve whose scalar field is the SNARK field, e.g. bn254 or bw6_761")
+	nbOutputs := flag.Int("outputs", 0, "number of outputs, only with -run (default: as used by the circuits)")
+	runOnly := flag.Bool("run", false, "only run the hint, without comparing with the reference")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] hint input...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *list {
+		for _, name := range hintdebug.Names() {
+			fmt.Println(name)
+		}
+		return
+	}
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	id, err := ecc.IDFromString(strings.ReplaceAll(*curve, "-", "_"))
+	if err != nil {
+		fatal(err)
+	}
+	field := id.ScalarField()
+
+	name := flag.Arg(0)
+	inputs := make([]*big.Int, flag.NArg()-1)
+	for i, arg := range flag.Args()[1:] {
+		var ok bool
+		if inputs[i], ok = new(big.Int).SetString(arg, 0); !ok {
+			fatal(fmt.Errorf("invalid input %q", arg))
+		}
+	}
+
+	if *runOnly {
+		n := *nbOutputs
+		if n == 0 {
+			if n, err = hintdebug.NbOutputs(name, inputs); err != nil {
+				fatal(err)
+			}
+		}
+		outputs, err := hintdebug.Run(name, field, inputs, n)
+		if err != nil {
+			fatal(err)
+		}
+		for i, x := range outputs {
+			fmt.Printf("output %2d   %s\n", i, x)
+		}
+		return
+	}
+
+	report, err := hintdebug.Check(name, field, inputs)
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Print(report)
+	if !report.OK() {
+		os.Exit(1)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
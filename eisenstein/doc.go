@@ -0,0 +1,12 @@
+// Package eisenstein provides arithmetic in Z[ω], the Eisenstein integers.
+//
+// Z[ω] form a commutative ring of algebraic integers in the algebraic number
+// field Q(√−3). These are of the form z = a + bω, where a and b are integers
+// and ω²+ω+1=0 (ω is a primitive cube root of unity). They are the
+// endomorphism ring of the j-invariant 0 curves (y²=x³+b) on which ω acts as
+// the cube-root endomorphism (x,y) → (βx,y).
+//
+// The package is a thin wrapper over the d=3 order of zzd, which uses the
+// generator ω' = (1+√−3)/2 = 1+ω of norm 1 and trace 1 (ω'²−ω'+1=0) instead.
+// ToZZD and FromZZD convert between the two bases.
+package eisenstein
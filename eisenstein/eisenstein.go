@@ -0,0 +1,170 @@
+package eisenstein
+
+import (
+	"math/big"
+
+	"github.com/yelhousni/jubjub-vs-bandersnatch/zzd"
+)
+
+// A ComplexNumber represents an arbitrary-precision Z[ω] integer, with
+// ω²+ω+1=0.
+type ComplexNumber struct {
+	A0, A1 *big.Int
+}
+
+// ring is Z[ω'] with ω' = (1+√−3)/2 = -ω² = 1+ω, the d=3 order of zzd.
+var ring *zzd.Ring
+
+func init() {
+	var err error
+	if ring, err = zzd.NewRing(3); err != nil {
+		panic(err)
+	}
+}
+
+// ToZZD returns z in the basis (1, ω') of the d=3 order of zzd, where
+// ω'=1+ω is the root of ω'²−ω'+1=0.
+//
+// The explicit formula is:
+//
+//	x0 + x1ω = (x0-x1) + x1ω'
+func (z *ComplexNumber) ToZZD() *zzd.Element {
+	a0 := new(big.Int).Sub(z.A0, z.A1)
+	return ring.NewElement(a0, z.A1)
+}
+
+// FromZZD sets z to the element x of the d=3 order of zzd, written in the
+// basis (1, ω) of z, and returns z. It panics if x belongs to another order.
+//
+// The explicit formula is:
+//
+//	x0 + x1ω' = (x0+x1) + x1ω
+func (z *ComplexNumber) FromZZD(x *zzd.Element) *ComplexNumber {
+	if r := x.Ring(); r != nil && r.D() != 3 {
+		panic("eisenstein: element of another order than Z[ω]")
+	}
+	a0 := new(big.Int).Add(x.A0, x.A1)
+	a1 := new(big.Int).Set(x.A1)
+	z.A0, z.A1 = a0, a1
+	return z
+}
+
+func (z *ComplexNumber) init() {
+	if z.A0 == nil {
+		z.A0 = new(big.Int)
+	}
+	if z.A1 == nil {
+		z.A1 = new(big.Int)
+	}
+}
+
+// String implements Stringer interface for fancy printing
+func (z *ComplexNumber) String() string {
+	return z.A0.String() + "+(" + z.A1.String() + "*ω)"
+}
+
+// Equal returns true if z equals x, false otherwise
+func (z *ComplexNumber) Equal(x *ComplexNumber) bool {
+	return z.A0.Cmp(x.A0) == 0 && z.A1.Cmp(x.A1) == 0
+}
+
+// Set sets z to x, and returns z.
+func (z *ComplexNumber) Set(x *ComplexNumber) *ComplexNumber {
+	z.init()
+	z.A0.Set(x.A0)
+	z.A1.Set(x.A1)
+	return z
+}
+
+// SetZero sets z to 0, and returns z.
+func (z *ComplexNumber) SetZero() *ComplexNumber {
+	z.A0 = big.NewInt(0)
+	z.A1 = big.NewInt(0)
+	return z
+}
+
+// SetOne sets z to 1, and returns z.
+func (z *ComplexNumber) SetOne() *ComplexNumber {
+	z.A0 = big.NewInt(1)
+	z.A1 = big.NewInt(0)
+	return z
+}
+
+// Neg sets z to the negative of x, and returns z.
+func (z *ComplexNumber) Neg(x *ComplexNumber) *ComplexNumber {
+	z.init()
+	z.A0.Neg(x.A0)
+	z.A1.Neg(x.A1)
+	return z
+}
+
+// Conjugate sets z to the conjugate of x, and returns z.
+//
+// The conjugate of ω is ω²=-1-ω, so the explicit formula is:
+//
+//	conj(x0+x1ω) = (x0-x1) - x1ω
+func (z *ComplexNumber) Conjugate(x *ComplexNumber) *ComplexNumber {
+	var c zzd.Element
+	c.Conjugate(x.ToZZD())
+	return z.FromZZD(&c)
+}
+
+// Add sets z to the sum of x and y, and returns z.
+func (z *ComplexNumber) Add(x, y *ComplexNumber) *ComplexNumber {
+	z.init()
+	z.A0.Add(x.A0, y.A0)
+	z.A1.Add(x.A1, y.A1)
+	return z
+}
+
+// Sub sets z to the difference of x and y, and returns z.
+func (z *ComplexNumber) Sub(x, y *ComplexNumber) *ComplexNumber {
+	z.init()
+	z.A0.Sub(x.A0, y.A0)
+	z.A1.Sub(x.A1, y.A1)
+	return z
+}
+
+// Mul sets z to the product of x and y, and returns z.
+//
+// Given that ω²+ω+1=0, the explicit formula is:
+//
+//	(x0+x1ω)(y0+y1ω) = (x0y0-x1y1) + (x0y1+x1y0-x1y1)ω
+func (z *ComplexNumber) Mul(x, y *ComplexNumber) *ComplexNumber {
+	var c zzd.Element
+	c.Mul(x.ToZZD(), y.ToZZD())
+	return z.FromZZD(&c)
+}
+
+// Norm returns the norm of z.
+//
+// The explicit formula is:
+//
+//	N(x0+x1ω) = x0² - x0x1 + x1²
+func (z *ComplexNumber) Norm() *big.Int {
+	return z.ToZZD().Norm()
+}
+
+// QuoRem sets z to the Euclidean quotient of x / y, r to the remainder,
+// and guarantees ‖r‖ < ‖y‖ (true Euclidean division in Z[ω]).
+//
+// The quotient is the one of zzd, i.e. the point of the hexagonal lattice
+// Z[ω] that is closest to x/y, so that N(r) ≤ N(y)/3.
+func (z *ComplexNumber) QuoRem(x, y, r *ComplexNumber) (*ComplexNumber, *ComplexNumber) {
+	var q, rem zzd.Element
+	q.QuoRem(x.ToZZD(), y.ToZZD(), &rem)
+	z.FromZZD(&q)
+	r.FromZZD(&rem)
+	return z, r
+}
+
+// HalfGCD returns the rational reconstruction of a, b.
+// This outputs w, v, u s.t. w = a*u + b*v.
+func HalfGCD(a, b *ComplexNumber) [3]*ComplexNumber {
+	res := zzd.HalfGCD(a.ToZZD(), b.ToZZD())
+	var out [3]*ComplexNumber
+	for i := range res {
+		out[i] = new(ComplexNumber).FromZZD(res[i])
+	}
+	return out
+}
@@ -0,0 +1,361 @@
+package eisenstein
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+	"github.com/yelhousni/jubjub-vs-bandersnatch/zzd"
+)
+
+const (
+	nbFuzzShort = 10
+	nbFuzz      = 50
+	boundSize   = 128
+)
+
+func TestEisensteinReceiverIsOperand(t *testing.T) {
+
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genE := GenComplexNumber(boundSize)
+
+	properties.Property("Having the receiver as operand (mul) should output the same result", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			var c, d ComplexNumber
+			d.Set(a)
+			c.Mul(a, b)
+			a.Mul(a, b)
+			b.Mul(&d, b)
+			return a.Equal(b) && a.Equal(&c) && b.Equal(&c)
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("Having the receiver as operand (conjugate) should output the same result", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b ComplexNumber
+			b.Conjugate(a)
+			a.Conjugate(a)
+			return a.Equal(&b)
+		},
+		genE,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestEisensteinArithmetic(t *testing.T) {
+
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genE := GenComplexNumber(boundSize)
+
+	properties.Property("ω should be a primitive cube root of unity", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var omega, c, one ComplexNumber
+			omega.A0, omega.A1 = big.NewInt(0), big.NewInt(1)
+			one.SetOne()
+			c.Mul(&omega, &omega).Mul(&c, &omega)
+			return c.Equal(&one)
+		},
+		genE,
+	))
+
+	properties.Property("conj twice should leave an element invariant", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b ComplexNumber
+			b.Conjugate(a).Conjugate(&b)
+			return a.Equal(&b)
+		},
+		genE,
+	))
+
+	properties.Property("mul should be commutative", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			var c, d ComplexNumber
+			c.Mul(a, b)
+			d.Mul(b, a)
+			return c.Equal(&d)
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("mul should be assiocative", prop.ForAll(
+		func(a, b, c *ComplexNumber) bool {
+			var d, e ComplexNumber
+			d.Mul(a, b).Mul(&d, c)
+			e.Mul(c, b).Mul(&e, a)
+			return e.Equal(&d)
+		},
+		genE,
+		genE,
+		genE,
+	))
+
+	properties.Property("z * conj(z) should be the norm of z", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b ComplexNumber
+			b.Conjugate(a).Mul(&b, a)
+			return b.A1.Sign() == 0 && b.A0.Cmp(a.Norm()) == 0
+		},
+		genE,
+	))
+
+	properties.Property("norm should always be positive", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			return a.Norm().Sign() >= 0
+		},
+		genE,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestEisensteinZZDConversion(t *testing.T) {
+
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genE := GenComplexNumber(boundSize)
+
+	properties.Property("ω should be ω'-1 in zzd", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			omega := ComplexNumber{A0: big.NewInt(0), A1: big.NewInt(1)}
+			w := omega.ToZZD()
+			return w.A0.Cmp(big.NewInt(-1)) == 0 && w.A1.Cmp(big.NewInt(1)) == 0
+		},
+		genE,
+	))
+
+	properties.Property("FromZZD should invert ToZZD", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b ComplexNumber
+			b.FromZZD(a.ToZZD())
+			return a.Equal(&b)
+		},
+		genE,
+	))
+
+	properties.Property("ToZZD should be a ring morphism", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			var c ComplexNumber
+			var d zzd.Element
+			c.Mul(a, b)
+			d.Mul(a.ToZZD(), b.ToZZD())
+			return c.ToZZD().Equal(&d) && a.Norm().Cmp(a.ToZZD().Norm()) == 0
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("Mul should match the explicit formula for ω²+ω+1=0", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			var c ComplexNumber
+			c.Mul(a, b)
+			var t, z0, z1 big.Int
+			t.Mul(a.A1, b.A1)
+			z0.Mul(a.A0, b.A0).Sub(&z0, &t)
+			z1.Mul(a.A0, b.A1).Sub(&z1, &t)
+			z1.Add(&z1, t.Mul(a.A1, b.A0))
+			return c.A0.Cmp(&z0) == 0 && c.A1.Cmp(&z1) == 0
+		},
+		genE,
+		genE,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestEisensteinQuoRem(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+	genE := GenComplexNumber(boundSize)
+	genS := GenComplexNumber(boundSize / 2)
+
+	properties.Property("QuoRem should be correct", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			var z, rem ComplexNumber
+			z.QuoRem(a, b, &rem)
+			var res ComplexNumber
+			res.Mul(b, &z)
+			res.Add(&res, &rem)
+			return res.Equal(a)
+		},
+		genE,
+		genS,
+	))
+
+	properties.Property("QuoRem remainder should be the closest one (N(r) ≤ N(y)/3)", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			var z, rem ComplexNumber
+			z.QuoRem(a, b, &rem)
+			lhs := new(big.Int).Mul(rem.Norm(), big.NewInt(3))
+			return lhs.Cmp(b.Norm()) <= 0
+		},
+		genE,
+		genS,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestEisensteinHalfGCD(t *testing.T) {
+
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genE := GenComplexNumber(boundSize)
+
+	properties.Property("half-GCD", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			res := HalfGCD(a, b)
+			var c, d ComplexNumber
+			c.Mul(b, res[1])
+			d.Mul(a, res[2])
+			d.Add(&c, &d)
+			return d.Equal(res[0])
+		},
+		genE,
+		genE,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// TestEisensteinHalfGCDBN254 checks the 4D decomposition
+//
+//	u1 + λ*u2 + s*(v1 + λ*v2) == 0 mod r
+//
+// for the BN254 G1 cube-root endomorphism, with u1, u2, v1, v2 ≈ r^¼.
+func TestEisensteinHalfGCDBN254(t *testing.T) {
+	t.Parallel()
+	r := ecc.BN254.ScalarField()
+
+	// λ is a primitive cube root of unity mod r, i.e. λ²+λ+1 = 0 mod r.
+	var lambda, e big.Int
+	e.Sub(r, big.NewInt(1)).Div(&e, big.NewInt(3))
+	for g := int64(2); ; g++ {
+		lambda.Exp(big.NewInt(g), &e, r)
+		if lambda.Cmp(big.NewInt(1)) != 0 {
+			break
+		}
+	}
+
+	// π = v1 + v2ω with v1 + λ*v2 = 0 mod r
+	var glvBasis ecc.Lattice
+	ecc.PrecomputeLattice(r, &lambda, &glvBasis)
+	pi := ComplexNumber{A0: &glvBasis.V1[0], A1: &glvBasis.V1[1]}
+
+	// bound ≈ 8·r^¼
+	var bound big.Int
+	bound.Sqrt(r).Sqrt(&bound).Lsh(&bound, 3)
+
+	for i := 0; i < nbFuzzShort; i++ {
+		s, _ := rand.Int(rand.Reader, r)
+		sp := ecc.SplitScalar(s, &glvBasis)
+		sz := ComplexNumber{A0: &sp[0], A1: &sp[1]}
+		sz.Neg(&sz)
+		res := HalfGCD(&pi, &sz)
+
+		var lhs, tmp big.Int
+		lhs.Mul(res[0].A1, &lambda).Add(&lhs, res[0].A0)
+		tmp.Mul(res[1].A1, &lambda).Add(&tmp, res[1].A0).Mul(&tmp, s)
+		lhs.Add(&lhs, &tmp).Mod(&lhs, r)
+		if lhs.Sign() != 0 {
+			t.Fatal("u1 + λ*u2 + s*(v1 + λ*v2) != 0 mod r")
+		}
+		for _, c := range []*big.Int{res[0].A0, res[0].A1, res[1].A0, res[1].A1} {
+			if new(big.Int).Abs(c).Cmp(&bound) > 0 {
+				t.Fatalf("component %s exceeds 8·r^¼", c.String())
+			}
+		}
+	}
+}
+
+// GenNumber generates a random signed integer
+func GenNumber(boundSize int64) gopter.Gen {
+	return func(genParams *gopter.GenParameters) *gopter.GenResult {
+		var bound big.Int
+		bound.Exp(big.NewInt(2), big.NewInt(boundSize), nil)
+		elmt, _ := rand.Int(genParams.Rng, &bound)
+		if genParams.NextBool() {
+			elmt.Neg(elmt)
+		}
+		genResult := gopter.NewGenResult(elmt, gopter.NoShrinker)
+		return genResult
+	}
+}
+
+// GenComplexNumber generates a random non-zero Eisenstein integer
+func GenComplexNumber(boundSize int64) gopter.Gen {
+	return gopter.CombineGens(
+		GenNumber(boundSize),
+		GenNumber(boundSize),
+	).Map(func(values []interface{}) *ComplexNumber {
+		a0, a1 := values[0].(*big.Int), values[1].(*big.Int)
+		if a0.Sign() == 0 && a1.Sign() == 0 {
+			a0.SetUint64(1)
+		}
+		return &ComplexNumber{A0: a0, A1: a1}
+	})
+}
+
+// bench
+var benchRes [3]*ComplexNumber
+
+func BenchmarkHalfGCD(b *testing.B) {
+	var n, _ = new(big.Int).SetString("100000000000000000000000000000000", 16) // 2^128
+	a0, _ := rand.Int(rand.Reader, n)
+	a1, _ := rand.Int(rand.Reader, n)
+	c0, _ := rand.Int(rand.Reader, n)
+	c1, _ := rand.Int(rand.Reader, n)
+	a := ComplexNumber{A0: a0, A1: a1}
+	c := ComplexNumber{A0: c0, A1: c1}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchRes = HalfGCD(&a, &c)
+	}
+}
@@ -0,0 +1,41 @@
+package zz2
+
+import (
+	"runtime"
+	"sync"
+)
+
+// BatchHalfGCD returns HalfGCDAuto(pairs[i][0], pairs[i][1]) for all i.
+//
+// The half-GCDs are computed by at most maxWorkers goroutines, or
+// runtime.NumCPU() goroutines if maxWorkers ≤ 0. The output order is the
+// input order regardless of the scheduling.
+func BatchHalfGCD(pairs [][2]*ComplexNumber, maxWorkers int) [][3]*ComplexNumber {
+	res := make([][3]*ComplexNumber, len(pairs))
+	if maxWorkers <= 0 {
+		maxWorkers = runtime.NumCPU()
+	}
+	if maxWorkers > len(pairs) {
+		maxWorkers = len(pairs)
+	}
+
+	jobs := make(chan int, len(pairs))
+	for i := range pairs {
+		jobs <- i
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	wg.Add(maxWorkers)
+	for w := 0; w < maxWorkers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				res[i] = HalfGCDAuto(pairs[i][0], pairs[i][1])
+			}
+		}()
+	}
+	wg.Wait()
+
+	return res
+}
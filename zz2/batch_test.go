@@ -0,0 +1,61 @@
+package zz2
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestBatchHalfGCD(t *testing.T) {
+	t.Parallel()
+	var n, _ = new(big.Int).SetString("100000000000000000000000000000000", 16) // 2^128
+	// the last pair does not fit in the fixed-limb arithmetic.
+	pairs := make([][2]*ComplexNumber, 33)
+	for i := range pairs {
+		bound := n
+		if i == len(pairs)-1 {
+			bound = new(big.Int).Lsh(n, 64)
+		}
+		var c [4]*big.Int
+		for j := range c {
+			c[j], _ = rand.Int(rand.Reader, bound)
+		}
+		pairs[i] = [2]*ComplexNumber{{A0: c[0], A1: c[1]}, {A0: c[2], A1: c[3]}}
+	}
+
+	for _, maxWorkers := range []int{0, 1, 4, 100} {
+		res := BatchHalfGCD(pairs, maxWorkers)
+		if len(res) != len(pairs) {
+			t.Fatalf("maxWorkers=%d: expected %d results, got %d", maxWorkers, len(pairs), len(res))
+		}
+		for i := range pairs {
+			expected := HalfGCD(pairs[i][0], pairs[i][1])
+			for j := range expected {
+				if !res[i][j].Equal(expected[j]) {
+					t.Fatalf("maxWorkers=%d: mismatch at pair %d", maxWorkers, i)
+				}
+			}
+		}
+	}
+
+	if res := BatchHalfGCD(nil, 0); len(res) != 0 {
+		t.Fatal("expected no result for an empty batch")
+	}
+}
+
+// bench
+func BenchmarkBatchHalfGCD(b *testing.B) {
+	var n, _ = new(big.Int).SetString("100000000000000000000000000000000", 16) // 2^128
+	pairs := make([][2]*ComplexNumber, 256)
+	for i := range pairs {
+		var c [4]*big.Int
+		for j := range c {
+			c[j], _ = rand.Int(rand.Reader, n)
+		}
+		pairs[i] = [2]*ComplexNumber{{A0: c[0], A1: c[1]}, {A0: c[2], A1: c[3]}}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = BatchHalfGCD(pairs, 0)
+	}
+}
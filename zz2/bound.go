@@ -0,0 +1,159 @@
+package zz2
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ErrBoundExceeded is returned when a component of the half-GCD or lifting
+// output exceeds the requested bound in absolute value.
+type ErrBoundExceeded struct {
+	// Component is the name of the offending component: "w.A0", "w.A1",
+	// "v.A0" or "v.A1" for w, v the first two outputs of HalfGCD, and "A0"
+	// or "A1" for the output of Lift.
+	Component string
+	Value     *big.Int
+	Bound     *big.Int
+}
+
+func (e *ErrBoundExceeded) Error() string {
+	return fmt.Sprintf("zz2: component %s = %s exceeds the bound %s", e.Component, e.Value, e.Bound)
+}
+
+// HalfGCDBound returns a bound B on the absolute value of the components of
+// w and v in HalfGCD(a, b) for N(a) = r and N(b) ≤ r, e.g. a = π generating
+// the prime ideal above r and b a scalar lifted modulo π. B ≈ 4·√√r.
+//
+// Proof. Write |x| = √N(x). The remainders r_k of the Euclidean algorithm
+// on r_{-1} = a and r_0 = b are r_k = a·u_k + b·v_k with (u_{-1}, v_{-1}) =
+// (1, 0) and (u_0, v_0) = (0, 1). As the quotient matrices have determinant
+// ±1,
+//
+//	v_k·r_{k-1} - v_{k-1}·r_k = ±a.
+//
+// QuoRem rounds both coordinates of the exact quotient, so that
+// N(r_{k+1}) ≤ 3/4·N(r_k), and |r_k| ≤ 3/4·|r_{k-2}|. By induction,
+// |v_k|·|r_{k-1}| ≤ 4·|a| for all k ≥ 0: it is |a| for k = 0, at most
+// |a| + |r_1| ≤ 2·|a| for k = 1 as |r_0| ≤ |a|, and at most
+// |a| + 3/4·|r_{k-2}|·|v_{k-1}| ≤ (1 + 3/4·4)·|a| for k ≥ 2.
+//
+// HalfGCD stops at the first r_k with N(r_k) < s = ⌊√r⌋ and returns
+// w = r_k and v = v_k. Then |w_i|² ≤ N(w) < s and, for k ≥ 1,
+// N(r_{k-1}) ≥ s so that |v_i|² ≤ N(v) ≤ 16·r/s, while v = 1 for k = 0.
+// Both are below B = ⌊√⌊16·r/s⌋⌋. The fixed-limb and constant-time
+// half-GCDs return the same w and v.
+//
+// The circuits range-check the components on r.BitLen()/4 + 9 bits, which
+// leaves a margin over B, and the hints check B with CheckBound so that an
+// output exceeding it is reported before proving.
+func HalfGCDBound(r *big.Int) *big.Int {
+	s := new(big.Int).Sqrt(r)
+	if s.Sign() == 0 {
+		// N(b) ≤ r = 0 only for b = 0, and HalfGCD returns w = 0, v = 1
+		return big.NewInt(1)
+	}
+	bound := new(big.Int).Lsh(r, 4)
+	bound.Quo(bound, s)
+	return bound.Sqrt(bound)
+}
+
+// CheckBound returns an *ErrBoundExceeded error if a component of w = res[0]
+// or v = res[1] exceeds bound in absolute value.
+func CheckBound(res [3]*ComplexNumber, bound *big.Int) error {
+	return checkComponents([]namedComponent{
+		{"w.A0", res[0].A0}, {"w.A1", res[0].A1},
+		{"v.A0", res[1].A0}, {"v.A1", res[1].A1},
+	}, bound)
+}
+
+// LiftBound returns a bound B on the absolute value of the components of the
+// short representative z of a scalar modulo π with N(π) = r, as returned by
+// Lift and LiftConstantTime. B = ⌊√r⌋.
+//
+// Proof. Both compute z = s - q·π where q rounds both coordinates of s/π to
+// the nearest integer, so that z = δ·π with |δ0|, |δ1| ≤ 1/2 and
+// N(z) = N(δ)·r ≤ 3/4·r. Then z0² ≤ N(z) < r and 2·z1² ≤ N(z) < r.
+func LiftBound(r *big.Int) *big.Int {
+	return new(big.Int).Sqrt(r)
+}
+
+// CheckLiftBound returns an *ErrBoundExceeded error if a component of z
+// exceeds bound in absolute value.
+func CheckLiftBound(z *ComplexNumber, bound *big.Int) error {
+	return checkComponents([]namedComponent{{"A0", z.A0}, {"A1", z.A1}}, bound)
+}
+
+type namedComponent struct {
+	name  string
+	value *big.Int
+}
+
+// checkComponents returns an *ErrBoundExceeded error for the first component
+// exceeding bound in absolute value.
+func checkComponents(components []namedComponent, bound *big.Int) error {
+	for _, c := range components {
+		if c.value.CmpAbs(bound) > 0 {
+			return &ErrBoundExceeded{
+				Component: c.name,
+				Value:     new(big.Int).Set(c.value),
+				Bound:     new(big.Int).Set(bound),
+			}
+		}
+	}
+	return nil
+}
+
+// HalfGCDBounded is HalfGCD that additionally checks that the components of
+// w and v are at most bound in absolute value. It returns an
+// *ErrBoundExceeded error otherwise.
+func HalfGCDBounded(a, b *ComplexNumber, bound *big.Int) ([3]*ComplexNumber, error) {
+	res := HalfGCD(a, b)
+	return res, CheckBound(res, bound)
+}
+
+// HalfGCDAutoBounded is HalfGCDAuto that additionally checks that the
+// components of w and v are at most bound in absolute value, with
+// HalfGCDFixedBounded or HalfGCDBounded. It returns an *ErrBoundExceeded
+// error otherwise.
+func HalfGCDAutoBounded(a, b *ComplexNumber, bound *big.Int) ([3]*ComplexNumber, error) {
+	var fixedBound FixedInt
+	if !fitsHalfGCDFixed(a, b) || bound.BitLen() > MaxHalfGCDFixedBits {
+		return HalfGCDBounded(a, b, bound)
+	}
+	var x, y FixedComplexNumber
+	x.SetComplexNumber(a)
+	y.SetComplexNumber(b)
+	fixedBound.SetBigInt(bound)
+	res, err := HalfGCDFixedBounded(&x, &y, &fixedBound)
+	return [3]*ComplexNumber{
+		res[0].ComplexNumber(),
+		res[1].ComplexNumber(),
+		res[2].ComplexNumber(),
+	}, err
+}
+
+// HalfGCDFixedBounded is HalfGCDFixed that additionally checks that the
+// components of w and v are at most bound in absolute value. It returns an
+// *ErrBoundExceeded error otherwise.
+func HalfGCDFixedBounded(a, b *FixedComplexNumber, bound *FixedInt) ([3]FixedComplexNumber, error) {
+	res := HalfGCDFixed(a, b)
+	components := [4]struct {
+		name  string
+		value *FixedInt
+	}{
+		{"w.A0", &res[0].A0}, {"w.A1", &res[0].A1},
+		{"v.A0", &res[1].A0}, {"v.A1", &res[1].A1},
+	}
+	for _, c := range components {
+		var abs FixedInt
+		abs.abs(c.value)
+		if ucmp(&abs, bound) > 0 {
+			return res, &ErrBoundExceeded{
+				Component: c.name,
+				Value:     c.value.BigInt(new(big.Int)),
+				Bound:     bound.BigInt(new(big.Int)),
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,133 @@
+package zz2
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/bandersnatch"
+)
+
+func TestHalfGCDBounded(t *testing.T) {
+	t.Parallel()
+	order := bandersnatch.GetEdwardsCurve().Order
+	lambda, _ := new(big.Int).SetString("8913659658109529928382530854484400854125314752504019737736543920008458395397", 10)
+	var glvBasis ecc.Lattice
+	ecc.PrecomputeLattice(&order, lambda, &glvBasis)
+	r := ComplexNumber{A0: &glvBasis.V1[0], A1: &glvBasis.V1[1]}
+
+	bound := HalfGCDBound(&order)
+	// the circuits range-check the components on BitLen/4 + 9 bits.
+	if bound.BitLen() > order.BitLen()/4+9 {
+		t.Fatalf("bound on %d bits does not fit the in-circuit range check", bound.BitLen())
+	}
+	var fixedBound FixedInt
+	fixedBound.SetBigInt(bound)
+
+	for i := 0; i < nbFuzz; i++ {
+		scalar, _ := rand.Int(rand.Reader, &order)
+		sp := ecc.SplitScalar(scalar, &glvBasis)
+		s := ComplexNumber{A0: &sp[0], A1: &sp[1]}
+		s.Neg(&s)
+		if _, err := HalfGCDBounded(&r, &s, bound); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := HalfGCDAutoBounded(&r, &s, bound); err != nil {
+			t.Fatal(err)
+		}
+		var x, y FixedComplexNumber
+		x.SetComplexNumber(&r)
+		y.SetComplexNumber(&s)
+		if _, err := HalfGCDFixedBounded(&x, &y, &fixedBound); err != nil {
+			t.Fatal(err)
+		}
+
+		// a bound that is too small must be reported with the offending
+		// component.
+		tooSmall := big.NewInt(1)
+		res, err := HalfGCDBounded(&r, &s, tooSmall)
+		var boundErr *ErrBoundExceeded
+		if !errors.As(err, &boundErr) {
+			t.Fatalf("expected ErrBoundExceeded, got %v", err)
+		}
+		if boundErr.Value.CmpAbs(tooSmall) <= 0 || boundErr.Bound.Cmp(tooSmall) != 0 {
+			t.Fatalf("unexpected error %v", boundErr)
+		}
+		var fixedTooSmall FixedInt
+		fixedTooSmall.SetBigInt(tooSmall)
+		_, err = HalfGCDFixedBounded(&x, &y, &fixedTooSmall)
+		var fixedBoundErr *ErrBoundExceeded
+		if !errors.As(err, &fixedBoundErr) || fixedBoundErr.Component != boundErr.Component ||
+			fixedBoundErr.Value.Cmp(boundErr.Value) != 0 {
+			t.Fatalf("fixed and arbitrary-precision errors differ: %v, %v (res %v)", err, boundErr, res)
+		}
+	}
+}
+
+// TestHalfGCDBoundSmallPrimes checks HalfGCDBound and LiftBound on every
+// scalar of the small primes above which Z[√−2] has prime ideals of degree
+// one.
+func TestHalfGCDBoundSmallPrimes(t *testing.T) {
+	t.Parallel()
+	for p := int64(3); p < 2000; p += 2 {
+		r := big.NewInt(p)
+		if !r.ProbablyPrime(0) || (p%8 != 1 && p%8 != 3) {
+			continue
+		}
+		lambda, err := sqrtMinusTwo(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pi, err := PrimeIdealGenerator(r, lambda)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bound := HalfGCDBound(r)
+		liftBound := LiftBound(r)
+		for s := int64(0); s < p; s++ {
+			var sz ComplexNumber
+			sz.Lift(big.NewInt(s), pi)
+			if err := CheckLiftBound(&sz, liftBound); err != nil {
+				t.Fatalf("r = %d, s = %d: %v", p, s, err)
+			}
+			sz.Neg(&sz)
+			if _, err := HalfGCDBounded(pi, &sz, bound); err != nil {
+				t.Fatalf("r = %d, s = %d: %v", p, s, err)
+			}
+		}
+	}
+}
+
+func TestLiftBound(t *testing.T) {
+	t.Parallel()
+	order := bandersnatch.GetEdwardsCurve().Order
+	lambda, _ := new(big.Int).SetString("8913659658109529928382530854484400854125314752504019737736543920008458395397", 10)
+	pi, err := PrimeIdealGenerator(&order, lambda)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bound := LiftBound(&order)
+	var piFixed FixedComplexNumber
+	piFixed.SetComplexNumber(pi)
+	for i := 0; i < nbFuzz; i++ {
+		scalar, _ := rand.Int(rand.Reader, &order)
+		var z ComplexNumber
+		z.Lift(scalar, pi)
+		if err := CheckLiftBound(&z, bound); err != nil {
+			t.Fatal(err)
+		}
+		var sFixed FixedInt
+		var zFixed FixedComplexNumber
+		sFixed.SetBigInt(scalar)
+		if err := CheckLiftBound(zFixed.LiftConstantTime(&sFixed, &piFixed).ComplexNumber(), bound); err != nil {
+			t.Fatal(err)
+		}
+
+		var boundErr *ErrBoundExceeded
+		if err := CheckLiftBound(&z, big.NewInt(1)); !errors.As(err, &boundErr) || boundErr.Component != "A0" && boundErr.Component != "A1" {
+			t.Fatalf("expected ErrBoundExceeded on A0 or A1, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package zz2
+
+import (
+	"math/bits"
+)
+
+// Constant-time arithmetic
+//
+// The functions below have no secret-dependent branches, memory accesses or
+// loop counts: the number of iterations only depends on public sizes and
+// conditional updates are done with masks. Only the inputs documented as
+// public (π, bit bounds) may influence the control flow.
+
+// wideLimbs is the number of limbs of the intermediate products of
+// LiftConstantTime (s·π ≈ 2^382).
+const wideLimbs = NbLimbs + 2
+
+// ctMask returns all ones if b == 1 and zero if b == 0.
+func ctMask(b uint64) uint64 {
+	return -b
+}
+
+// ctSelect sets z[i] to x[i] if mask is all ones and to y[i] if mask is zero.
+func ctSelect(z, x, y []uint64, mask uint64) {
+	for i := range z {
+		z[i] = y[i] ^ (mask & (x[i] ^ y[i]))
+	}
+}
+
+// ctNegIf sets z to -x if mask is all ones and to x if mask is zero.
+func ctNegIf(z, x []uint64, mask uint64) {
+	// -x = ^x + 1
+	c := mask & 1
+	for i := range z {
+		z[i], c = bits.Add64(x[i]^mask, 0, c)
+	}
+}
+
+// ctMul sets z to x*y truncated to len(z) limbs. x and y must have len(z)
+// limbs.
+func ctMul(z, x, y []uint64) {
+	var buf [wideLimbs]uint64
+	res := buf[:len(z)]
+	for i := range z {
+		var carry uint64
+		for j := 0; i+j < len(z); j++ {
+			hi, lo := bits.Mul64(x[i], y[j])
+			var c uint64
+			lo, c = bits.Add64(lo, res[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			res[i+j] = lo
+			carry = hi
+		}
+	}
+	copy(z, res)
+}
+
+// ctDivisor is a divisor of ctUDiv, normalised once for several divisions.
+type ctDivisor struct {
+	vn  [wideLimbs]uint64 // v·2^sh, with its top limb's high bit set
+	l   int               // number of limbs of v
+	sh  uint64
+	rec uint64 // ctReciprocal of the top limb of vn
+}
+
+// set prepares the division by the unsigned v of at most wideLimbs limbs.
+func (dv *ctDivisor) set(v []uint64) *ctDivisor {
+	dv.l = len(v)
+	dv.sh = ctLeadingZeros(v)
+	copy(dv.vn[:], v)
+	ctShl(dv.vn[:dv.l], dv.sh, 64*dv.l)
+	dv.rec = ctReciprocal(dv.vn[dv.l-1])
+	return dv
+}
+
+// ctUDiv sets q to ⌊u/v⌋ for unsigned u, v of the same length ℓ ≤ wideLimbs.
+// q must have ℓ limbs. The result is garbage, but no panic occurs, if v = 0.
+func ctUDiv(q, u, v []uint64) {
+	var dv ctDivisor
+	dv.set(v).quo(q, u)
+}
+
+// quo sets q to ⌊u/v⌋ for u, q of the length ℓ of v. It runs the ℓ+1 steps
+// of Knuth's algorithm D on the normalised operands, each with a quotient
+// digit estimated without hardware division and two masked add-backs.
+func (dv *ctDivisor) quo(q, u []uint64) {
+	l := dv.l
+	vn := dv.vn[:l]
+	var unBuf [2*wideLimbs + 1]uint64
+	un := unBuf[:2*l+1]
+	// u·2^sh fits in 2ℓ limbs.
+	copy(un, u)
+	ctShl(un[:2*l], dv.sh, 64*l)
+
+	d := vn[l-1]
+	for j := l; j >= 0; j-- {
+		// D3: estimate q̂ = min(⌊(u_{j+ℓ}·β + u_{j+ℓ-1}) / d⌋, β-1), which is
+		// at most 2 above the quotient digit (Knuth, Theorem 4.3.1B). The
+		// top limb is at most d, and the division only needs it when it is
+		// smaller.
+		u1 := un[j+l]
+		eq := ctEq(u1, d)
+		qhat := ctDiv2By1(u1&^eq, un[j+l-1], d, dv.rec) | eq
+
+		// D4: multiply and subtract.
+		var mulCarry, borrow uint64
+		for i := 0; i < l; i++ {
+			hi, lo := bits.Mul64(qhat, vn[i])
+			var c uint64
+			lo, c = bits.Add64(lo, mulCarry, 0)
+			mulCarry = hi + c
+			un[j+i], borrow = bits.Sub64(un[j+i], lo, borrow)
+		}
+		un[j+l], borrow = bits.Sub64(un[j+l], mulCarry, borrow)
+
+		// D6: add v back while the partial remainder is negative, i.e.
+		// until an addition carries out.
+		neg := ctMask(borrow)
+		for k := 0; k < 2; k++ {
+			var c uint64
+			for i := 0; i < l; i++ {
+				un[j+i], c = bits.Add64(un[j+i], vn[i]&neg, c)
+			}
+			un[j+l], c = bits.Add64(un[j+l], 0, c)
+			qhat -= neg & 1
+			neg &= c - 1
+		}
+		// the digit q_ℓ is zero since u < β^ℓ.
+		if j < l {
+			q[j] = qhat
+		}
+	}
+}
+
+// ctEq returns all ones if x == y and zero otherwise.
+func ctEq(x, y uint64) uint64 {
+	z := x ^ y
+	return ((z | -z) >> 63) - 1
+}
+
+// ctLeadingZeros returns the number of leading zero bits of the unsigned
+// integer x, i.e. 64·len(x) if x = 0.
+func ctLeadingZeros(x []uint64) uint64 {
+	// bits.LeadingZeros64 compiles to LZCNT, or BSR and a conditional move.
+	var n, done uint64
+	for i := len(x) - 1; i >= 0; i-- {
+		n += ^done & uint64(bits.LeadingZeros64(x[i]))
+		done |= ^ctEq(x[i], 0)
+	}
+	return n
+}
+
+// ctShl sets x to x·2^sh truncated to len(x) limbs, for a secret sh at most
+// maxShift. The shift by sh mod 64 is a single pass, Go shifts by 64 or more
+// giving zero without branching; the limbs are moved by masked shifts by
+// 1, 2, 4, … limbs.
+func ctShl(x []uint64, sh uint64, maxShift int) {
+	bitShift := sh % 64
+	for i := len(x) - 1; i > 0; i-- {
+		x[i] = x[i]<<bitShift | x[i-1]>>(64-bitShift)
+	}
+	x[0] <<= bitShift
+
+	var tBuf [2*wideLimbs + 1]uint64
+	t := tBuf[:len(x)]
+	for k := 0; 64<<k <= maxShift; k++ {
+		limbs := 1 << k
+		for i := range t {
+			t[i] = 0
+			if i >= limbs {
+				t[i] = x[i-limbs]
+			}
+		}
+		ctSelect(x, t, x, ctMask((sh>>(6+k))&1))
+	}
+}
+
+// ctReciprocal returns ⌊(β²-1)/d⌋ - β for a normalised d, i.e. with its high
+// bit set, by restoring division of (β-1-d)·β + β-1 by d.
+func ctReciprocal(d uint64) uint64 {
+	var q uint64
+	r := ^d
+	for i := 63; i >= 0; i-- {
+		// r = 2r + 1 may overflow; subtract d if it does or if r ≥ d.
+		top := r >> 63
+		r = r<<1 | 1
+		t, b := bits.Sub64(r, d, 0)
+		m := ctMask(top | (b ^ 1))
+		r ^= m & (r ^ t)
+		q |= (m & 1) << i
+	}
+	return q
+}
+
+// ctDiv2By1 returns ⌊(u1·β + u0) / d⌋ for a normalised d, u1 < d and
+// rec = ctReciprocal(d) (Möller and Granlund, Improved division by invariant
+// integers, Algorithm 4), with masked corrections.
+func ctDiv2By1(u1, u0, d, rec uint64) uint64 {
+	q1, q0 := bits.Mul64(rec, u1)
+	var c uint64
+	q0, c = bits.Add64(q0, u0, 0)
+	q1, _ = bits.Add64(q1, u1+1, c)
+	r := u0 - q1*d
+	// if r > q0 { q1--; r += d }
+	_, b := bits.Sub64(q0, r, 0)
+	m := ctMask(b)
+	q1 -= m & 1
+	r += m & d
+	// if r ≥ d { q1++ }
+	_, b = bits.Sub64(r, d, 0)
+	q1 += ctMask(b^1) & 1
+	return q1
+}
+
+// ctGeq returns all ones if x ≥ y and zero otherwise, for non-negative x, y.
+func ctGeq(x, y *FixedInt) uint64 {
+	var borrow uint64
+	for i := 0; i < NbLimbs; i++ {
+		_, borrow = bits.Sub64(x[i], y[i], borrow)
+	}
+	return borrow - 1
+}
+
+// ctRoundNearest sets z to ⌊(x + d/2) / d⌋ for *any* sign of x, d>0, like
+// roundNearestFixed but without branching on the sign of x.
+func ctRoundNearest(z, x, d *FixedInt) *FixedInt {
+	var dv ctDivisor
+	return ctRoundNearestBy(z, x, d, dv.set(d[:]))
+}
+
+// ctRoundNearestBy is ctRoundNearest with d prepared in dv.
+func ctRoundNearestBy(z, x, d *FixedInt, dv *ctDivisor) *FixedInt {
+	var half, num FixedInt
+	half = *d
+	for i := 0; i < NbLimbs-1; i++ {
+		half[i] = half[i]>>1 | half[i+1]<<63
+	}
+	half[NbLimbs-1] >>= 1
+	neg := ctMask(x[NbLimbs-1] >> 63)
+	ctNegIf(num[:], x[:], neg)
+	num.add(&num, &half)
+	dv.quo(z[:], num[:])
+	ctNegIf(z[:], z[:], neg)
+	return z
+}
+
+// QuoRemConstantTime sets z to the Euclidean quotient of x / y, r to the
+// remainder, and guarantees ‖r‖ < ‖y‖, in constant time.
+//
+// In Z[√−2] rounding both coordinates of x/y already gives
+// N(r) ≤ (1/4 + 2/4)·N(y) < N(y), so unlike QuoRem there is no neighbour
+// search and the result is the same as QuoRem's. The result is garbage, but
+// no panic occurs, if y = 0.
+func (z *FixedComplexNumber) QuoRemConstantTime(x, y, r *FixedComplexNumber) (*FixedComplexNumber, *FixedComplexNumber) {
+	norm := y.Norm()
+
+	// num = x * ȳ
+	var yConj, num, q, rem FixedComplexNumber
+	yConj.Conjugate(y)
+	num.Mul(x, &yConj)
+
+	// symmetric rounding of both coordinates
+	var dv ctDivisor
+	dv.set(norm[:])
+	ctRoundNearestBy(&q.A0, &num.A0, &norm, &dv)
+	ctRoundNearestBy(&q.A1, &num.A1, &norm, &dv)
+
+	// r = x – q*y
+	rem.Mul(y, &q)
+	rem.Sub(x, &rem)
+
+	*z = q
+	*r = rem
+	return z, r
+}
+
+// LiftConstantTime sets z to the short representative of the scalar
+// s ∈ [0, 2^255) modulo π, like Lift, in constant time in s. π is public and
+// its components must be at most 128 bits long.
+func (z *FixedComplexNumber) LiftConstantTime(s *FixedInt, pi *FixedComplexNumber) *FixedComplexNumber {
+	norm := pi.Norm()
+
+	// s/π = s·π̄/N(π) = s·π0/N(π) - (s·π1/N(π))j. The signs of the
+	// numerators are the public signs of π0 and -π1.
+	var q FixedComplexNumber
+	for _, c := range [2]struct {
+		num *FixedInt
+		neg bool
+		res *FixedInt
+	}{
+		{&pi.A0, pi.A0.isNeg(), &q.A0},
+		{&pi.A1, !pi.A1.isNeg(), &q.A1},
+	} {
+		var abs FixedInt
+		abs.abs(c.num)
+
+		var sw, absw, normw, num, quo [wideLimbs]uint64
+		copy(sw[:], s[:])
+		copy(absw[:], abs[:])
+		copy(normw[:], norm[:])
+		ctMul(num[:], sw[:], absw[:])
+
+		// ⌊(s·|π_i| + N/2) / N⌋
+		var half [wideLimbs]uint64
+		copy(half[:], norm[:])
+		for k := 0; k < wideLimbs-1; k++ {
+			half[k] = half[k]>>1 | half[k+1]<<63
+		}
+		var carry uint64
+		for k := range num {
+			num[k], carry = bits.Add64(num[k], half[k], carry)
+		}
+		ctUDiv(quo[:], num[:], normw[:])
+		copy(c.res[:], quo[:NbLimbs])
+		if c.neg {
+			c.res.neg(c.res)
+		}
+	}
+
+	// z = s - q*π
+	var x FixedComplexNumber
+	x.A0 = *s
+	z.Mul(pi, &q)
+	z.Sub(&x, z)
+	return z
+}
+
+// nbIterationsConstantTime returns the number of Euclidean steps that is
+// enough to bring a norm of at most normBits bits below 2^(sqrtBitLen-1).
+// Each step multiplies the norm by at most 3/4 and 1/log2(4/3) < 2.41.
+func nbIterationsConstantTime(normBits, sqrtBitLen int) int {
+	gap := normBits - sqrtBitLen + 1
+	if gap < 0 {
+		return 0
+	}
+	return (gap*241+99)/100 + 1
+}
+
+// HalfGCDConstantTime returns the rational reconstruction of a, b like
+// HalfGCDFixed, in constant time in b.
+//
+// a is public (e.g. a generator π of a prime ideal) and normBits is a public
+// bound on the bit length of N(b). The loop always runs the number of
+// iterations that is needed in the worst case, the steps after the stopping
+// condition being computed and discarded.
+func HalfGCDConstantTime(a, b *FixedComplexNumber, normBits int) [3]FixedComplexNumber {
+	var aRun, bRun, u, v, u_, v_, quotient, remainder, t, t1, t2 FixedComplexNumber
+	var sqrt FixedInt
+
+	aRun.Set(a)
+	bRun.Set(b)
+	u.SetOne()
+	v.SetZero()
+	u_.SetZero()
+	v_.SetOne()
+
+	// a is public so neither the square root nor its size are secret.
+	aNorm := a.Norm()
+	sqrtFixed(&sqrt, &aNorm)
+	sqrtBitLen := 0
+	for i := NbLimbs - 1; i >= 0; i-- {
+		if sqrt[i] != 0 {
+			sqrtBitLen = 64*i + bits.Len64(sqrt[i])
+			break
+		}
+	}
+
+	n := nbIterationsConstantTime(normBits, sqrtBitLen)
+	for i := 0; i < n; i++ {
+		bNorm := bRun.Norm()
+		cont := ctGeq(&bNorm, &sqrt)
+
+		quotient.QuoRemConstantTime(&aRun, &bRun, &remainder)
+		t.Mul(&u_, &quotient)
+		t1.Sub(&u, &t)
+		t.Mul(&v_, &quotient)
+		t2.Sub(&v, &t)
+
+		ctSet(&aRun, &bRun, cont)
+		ctSet(&u, &u_, cont)
+		ctSet(&v, &v_, cont)
+		ctSet(&bRun, &remainder, cont)
+		ctSet(&u_, &t1, cont)
+		ctSet(&v_, &t2, cont)
+	}
+
+	return [3]FixedComplexNumber{bRun, v_, u_}
+}
+
+// ctSet sets z to x if mask is all ones and leaves z unchanged if mask is
+// zero.
+func ctSet(z, x *FixedComplexNumber, mask uint64) {
+	ctSelect(z.A0[:], x.A0[:], z.A0[:], mask)
+	ctSelect(z.A1[:], x.A1[:], z.A1[:], mask)
+}
@@ -0,0 +1,177 @@
+package zz2
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/bandersnatch"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestConstantTimeMatchesFixed(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	order := bandersnatch.GetEdwardsCurve().Order
+	lambda, _ := new(big.Int).SetString(bandersnatchLambda, 10)
+	pi, err := PrimeIdealGenerator(&order, lambda)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var piFixed FixedComplexNumber
+	piFixed.SetComplexNumber(pi)
+
+	properties := gopter.NewProperties(parameters)
+
+	genE := GenSignedComplexNumber(boundSize)
+	genS := GenNumber(int64(order.BitLen()))
+
+	properties.Property("ctUDiv should match big.Int division", prop.ForAll(
+		func(a, b *big.Int, shift uint) bool {
+			b = new(big.Int).Rsh(b, shift)
+			if b.Sign() == 0 {
+				return true
+			}
+			var u, v, q [wideLimbs]uint64
+			for i, w := range a.Bits() {
+				u[i] = uint64(w)
+			}
+			for i, w := range b.Bits() {
+				v[i] = uint64(w)
+			}
+			ctUDiv(q[:], u[:], v[:])
+			expected := new(big.Int).Quo(a, b)
+			got := new(big.Int)
+			for i := wideLimbs - 1; i >= 0; i-- {
+				got.Lsh(got, 64).Or(got, new(big.Int).SetUint64(q[i]))
+			}
+			return got.Cmp(expected) == 0
+		},
+		GenNumber(64*wideLimbs),
+		GenNumber(64*wideLimbs),
+		gen.UIntRange(0, 64*wideLimbs-1),
+	))
+
+	properties.Property("ctRoundNearest should match roundNearestFixed", prop.ForAll(
+		func(a, b *big.Int) bool {
+			b = new(big.Int).Abs(b)
+			if b.Sign() == 0 {
+				return true
+			}
+			var x, d, z1, z2 FixedInt
+			x.SetBigInt(a)
+			d.SetBigInt(b)
+			roundNearestFixed(&z1, &x, &d)
+			ctRoundNearest(&z2, &x, &d)
+			return z1 == z2
+		},
+		GenSignedNumber(2*boundSize),
+		GenSignedNumber(boundSize),
+	))
+
+	properties.Property("QuoRemConstantTime should match QuoRem", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			if b.A0.Sign() == 0 && b.A1.Sign() == 0 {
+				return true
+			}
+			var x, y, q1, r1, q2, r2 FixedComplexNumber
+			x.SetComplexNumber(a)
+			y.SetComplexNumber(b)
+			q1.QuoRem(&x, &y, &r1)
+			q2.QuoRemConstantTime(&x, &y, &r2)
+			return q1 == q2 && r1 == r2
+		},
+		genE,
+		GenSignedComplexNumber(boundSize/2),
+	))
+
+	properties.Property("LiftConstantTime should match Lift", prop.ForAll(
+		func(s *big.Int) bool {
+			var sFixed FixedInt
+			sFixed.SetBigInt(s)
+			var z FixedComplexNumber
+			z.LiftConstantTime(&sFixed, &piFixed)
+			var expected ComplexNumber
+			expected.Lift(s, pi)
+			return z.ComplexNumber().Equal(&expected)
+		},
+		genS,
+	))
+
+	properties.Property("HalfGCDConstantTime should match HalfGCDFixed", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			var x, y FixedComplexNumber
+			x.SetComplexNumber(a)
+			y.SetComplexNumber(b)
+			res := HalfGCDConstantTime(&x, &y, b.Norm().BitLen())
+			expected := HalfGCDFixed(&x, &y)
+			return res == expected
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("the constant-time decomposition should match the variable-time one", prop.ForAll(
+		func(s *big.Int) bool {
+			var sFixed FixedInt
+			sFixed.SetBigInt(s)
+			var b FixedComplexNumber
+			b.LiftConstantTime(&sFixed, &piFixed).Neg(&b)
+			res := HalfGCDConstantTime(&piFixed, &b, order.BitLen())
+
+			var c ComplexNumber
+			c.Lift(s, pi).Neg(&c)
+			expected := HalfGCD(pi, &c)
+			for i := range res {
+				if !res[i].ComplexNumber().Equal(expected[i]) {
+					return false
+				}
+			}
+			return true
+		},
+		genS,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestHalfGCDConstantTimeDoesNotAllocate(t *testing.T) {
+	var n, _ = new(big.Int).SetString("100000000000000000000000000000000", 16) // 2^128
+	a0, _ := rand.Int(rand.Reader, n)
+	a1, _ := rand.Int(rand.Reader, n)
+	c0, _ := rand.Int(rand.Reader, n)
+	c1, _ := rand.Int(rand.Reader, n)
+	var a, c FixedComplexNumber
+	a.SetComplexNumber(&ComplexNumber{A0: a0, A1: a1})
+	c.SetComplexNumber(&ComplexNumber{A0: c0, A1: c1})
+	allocs := testing.AllocsPerRun(10, func() {
+		benchResFixed = HalfGCDConstantTime(&a, &c, 258)
+	})
+	if allocs != 0 {
+		t.Fatalf("HalfGCDConstantTime allocates %v times", allocs)
+	}
+}
+
+func BenchmarkHalfGCDConstantTime(b *testing.B) {
+	var n, _ = new(big.Int).SetString("100000000000000000000000000000000", 16) // 2^128
+	a0, _ := rand.Int(rand.Reader, n)
+	a1, _ := rand.Int(rand.Reader, n)
+	c0, _ := rand.Int(rand.Reader, n)
+	c1, _ := rand.Int(rand.Reader, n)
+	var a, c FixedComplexNumber
+	a.SetComplexNumber(&ComplexNumber{A0: a0, A1: a1})
+	c.SetComplexNumber(&ComplexNumber{A0: c0, A1: c1})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchResFixed = HalfGCDConstantTime(&a, &c, 258)
+	}
+}
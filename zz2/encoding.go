@@ -0,0 +1,149 @@
+package zz2
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+const (
+	// SizeComponent is the size in bytes of an encoded component: a signed
+	// big-endian two's complement integer in [-2^255, 2^255).
+	SizeComponent = 32
+	// SizeComplexNumber is the size in bytes of an encoded ComplexNumber:
+	// the encoding of A0 followed by the encoding of A1.
+	SizeComplexNumber = 2 * SizeComponent
+)
+
+var (
+	// ErrEncodingOverflow is returned when a component does not fit in
+	// SizeComponent bytes.
+	ErrEncodingOverflow = errors.New("zz2: component does not fit in 256 bits")
+	// ErrInvalidEncoding is returned when decoding malformed data.
+	ErrInvalidEncoding = errors.New("zz2: invalid encoding")
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is
+// canonical: each component is written as a SizeComponent-byte big-endian
+// two's complement integer, A0 first. As for all the encodings, a nil
+// component is encoded as zero, so that the zero value encodes as 0.
+func (z *ComplexNumber) MarshalBinary() ([]byte, error) {
+	res := make([]byte, SizeComplexNumber)
+	if err := putComponent(res[:SizeComponent], component(z.A0)); err != nil {
+		return nil, err
+	}
+	if err := putComponent(res[SizeComponent:], component(z.A1)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (z *ComplexNumber) UnmarshalBinary(data []byte) error {
+	if len(data) != SizeComplexNumber {
+		return ErrInvalidEncoding
+	}
+	z.init()
+	getComponent(z.A0, data[:SizeComponent])
+	getComponent(z.A1, data[SizeComponent:])
+	return nil
+}
+
+// component returns x, or 0 if x is nil.
+func component(x *big.Int) *big.Int {
+	if x == nil {
+		return new(big.Int)
+	}
+	return x
+}
+
+// putComponent writes x to buf as a big-endian two's complement integer.
+func putComponent(buf []byte, x *big.Int) error {
+	// -2^255 ≤ x < 2^255
+	if x.Sign() >= 0 && x.BitLen() >= 8*SizeComponent ||
+		x.Sign() < 0 && new(big.Int).Add(x, big.NewInt(1)).BitLen() >= 8*SizeComponent {
+		return ErrEncodingOverflow
+	}
+	if x.Sign() >= 0 {
+		x.FillBytes(buf)
+		return nil
+	}
+	// 2^256 + x
+	var t big.Int
+	t.Lsh(big.NewInt(1), 8*SizeComponent).Add(&t, x)
+	t.FillBytes(buf)
+	return nil
+}
+
+// getComponent sets z to the big-endian two's complement integer in buf.
+func getComponent(z *big.Int, buf []byte) {
+	z.SetBytes(buf)
+	if buf[0]&0x80 != 0 {
+		var t big.Int
+		t.Lsh(big.NewInt(1), 8*SizeComponent)
+		z.Sub(z, &t)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler with the "a+(b*j)" form of
+// String.
+func (z *ComplexNumber) MarshalText() ([]byte, error) {
+	return []byte(component(z.A0).String() + "+(" + component(z.A1).String() + "*j)"), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (z *ComplexNumber) UnmarshalText(text []byte) error {
+	if _, ok := z.SetString(string(text)); !ok {
+		return ErrInvalidEncoding
+	}
+	return nil
+}
+
+// complexNumberJSON is the JSON form of a ComplexNumber. The components are
+// decimal strings so that no precision is lost by JSON decoders.
+type complexNumberJSON struct {
+	A0 string `json:"a0"`
+	A1 string `json:"a1"`
+}
+
+// MarshalJSON implements json.Marshaler as {"a0":"<dec>","a1":"<dec>"}.
+func (z *ComplexNumber) MarshalJSON() ([]byte, error) {
+	return json.Marshal(complexNumberJSON{A0: component(z.A0).String(), A1: component(z.A1).String()})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (z *ComplexNumber) UnmarshalJSON(data []byte) error {
+	var v complexNumberJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	var a0, a1 big.Int
+	if _, ok := a0.SetString(v.A0, 10); !ok {
+		return ErrInvalidEncoding
+	}
+	if _, ok := a1.SetString(v.A1, 10); !ok {
+		return ErrInvalidEncoding
+	}
+	z.A0, z.A1 = &a0, &a1
+	return nil
+}
+
+// SetString sets z to the value of s in the "a+(b*j)" form of String, where
+// a and b are signed decimal integers, and returns z and a boolean indicating
+// success. On failure z is left unchanged.
+func (z *ComplexNumber) SetString(s string) (*ComplexNumber, bool) {
+	i := strings.Index(s, "+(")
+	if i <= 0 || !strings.HasSuffix(s, "*j)") {
+		return nil, false
+	}
+	var a0, a1 big.Int
+	if _, ok := a0.SetString(s[:i], 10); !ok {
+		return nil, false
+	}
+	if _, ok := a1.SetString(s[i+2:len(s)-3], 10); !ok {
+		return nil, false
+	}
+	z.A0, z.A1 = &a0, &a1
+	return z, true
+}
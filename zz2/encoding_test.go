@@ -0,0 +1,179 @@
+package zz2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestZZ2Encoding(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genE := GenSignedComplexNumber(8*SizeComponent - 1)
+
+	properties.Property("UnmarshalBinary(MarshalBinary) should leave an element invariant", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			data, err := a.MarshalBinary()
+			if err != nil || len(data) != SizeComplexNumber {
+				return false
+			}
+			var b ComplexNumber
+			return b.UnmarshalBinary(data) == nil && a.Equal(&b)
+		},
+		genE,
+	))
+
+	properties.Property("UnmarshalText(MarshalText) should leave an element invariant", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			data, err := a.MarshalText()
+			if err != nil {
+				return false
+			}
+			var b ComplexNumber
+			return b.UnmarshalText(data) == nil && a.Equal(&b)
+		},
+		genE,
+	))
+
+	properties.Property("json.Unmarshal(json.Marshal) should leave an element invariant", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			data, err := json.Marshal(a)
+			if err != nil {
+				return false
+			}
+			var b ComplexNumber
+			return json.Unmarshal(data, &b) == nil && a.Equal(&b)
+		},
+		genE,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestZZ2EncodingVectors(t *testing.T) {
+	t.Parallel()
+
+	a := ComplexNumber{A0: big.NewInt(-1), A1: big.NewInt(258)}
+	data, err := a.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := bytes.Repeat([]byte{0xff}, SizeComponent)
+	expected = append(expected, make([]byte, SizeComponent-2)...)
+	expected = append(expected, 0x01, 0x02)
+	if !bytes.Equal(data, expected) {
+		t.Fatalf("unexpected binary encoding %s", hex.EncodeToString(data))
+	}
+
+	data, _ = json.Marshal(&a)
+	if string(data) != `{"a0":"-1","a1":"258"}` {
+		t.Fatalf("unexpected JSON encoding %s", data)
+	}
+
+	// bounds of the binary encoding
+	min := new(big.Int).Lsh(big.NewInt(1), 8*SizeComponent-1)
+	min.Neg(min)
+	max := new(big.Int).Not(min)
+	for _, c := range []*big.Int{min, max} {
+		var b ComplexNumber
+		x := ComplexNumber{A0: c, A1: new(big.Int).Neg(max)}
+		data, err := x.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.UnmarshalBinary(data) != nil || !b.Equal(&x) {
+			t.Fatalf("%s does not round-trip", x.String())
+		}
+	}
+	for _, c := range []*big.Int{new(big.Int).Sub(min, big.NewInt(1)), new(big.Int).Add(max, big.NewInt(1))} {
+		x := ComplexNumber{A0: big.NewInt(0), A1: c}
+		if _, err := x.MarshalBinary(); err != ErrEncodingOverflow {
+			t.Fatalf("expected ErrEncodingOverflow for %s", c.String())
+		}
+	}
+	var b ComplexNumber
+	if b.UnmarshalBinary(make([]byte, SizeComplexNumber-1)) != ErrInvalidEncoding {
+		t.Fatal("expected ErrInvalidEncoding for a short input")
+	}
+}
+
+func TestZZ2EncodingZeroValue(t *testing.T) {
+	t.Parallel()
+
+	// the zero value has nil components and encodes as 0
+	var zero ComplexNumber
+	zero.SetZero()
+	for _, x := range []ComplexNumber{{}, {A0: big.NewInt(0)}, {A1: big.NewInt(0)}} {
+		data, err := x.MarshalBinary()
+		if err != nil || !bytes.Equal(data, make([]byte, SizeComplexNumber)) {
+			t.Fatalf("MarshalBinary: %x, %v", data, err)
+		}
+		var b ComplexNumber
+		if err := b.UnmarshalBinary(data); err != nil || !b.Equal(&zero) {
+			t.Fatalf("UnmarshalBinary: %v, %v", b.String(), err)
+		}
+
+		text, err := x.MarshalText()
+		if err != nil || string(text) != "0+(0*j)" {
+			t.Fatalf("MarshalText: %s, %v", text, err)
+		}
+		var c ComplexNumber
+		if err := c.UnmarshalText(text); err != nil || !c.Equal(&zero) {
+			t.Fatalf("UnmarshalText: %v, %v", c.String(), err)
+		}
+
+		js, err := json.Marshal(&x)
+		if err != nil || string(js) != `{"a0":"0","a1":"0"}` {
+			t.Fatalf("MarshalJSON: %s, %v", js, err)
+		}
+		var d ComplexNumber
+		if err := json.Unmarshal(js, &d); err != nil || !d.Equal(&zero) {
+			t.Fatalf("UnmarshalJSON: %v, %v", d.String(), err)
+		}
+	}
+}
+
+func TestZZ2SetString(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		in     string
+		a0, a1 int64
+	}{
+		{"0+(0*j)", 0, 0},
+		{"3+(-5*j)", 3, -5},
+		{"-7+(2*j)", -7, 2},
+		{"+7+(+2*j)", 7, 2},
+	} {
+		var z ComplexNumber
+		if _, ok := z.SetString(tc.in); !ok {
+			t.Fatalf("could not parse %q", tc.in)
+		}
+		if z.A0.Int64() != tc.a0 || z.A1.Int64() != tc.a1 {
+			t.Fatalf("%q parsed as %s", tc.in, z.String())
+		}
+	}
+
+	for _, in := range []string{"", "3", "3+(5)", "+(5*j)", "3+(*j)", "3+(5*j", "3 +(5*j)", "a+(5*j)", "3+(5*j)x"} {
+		var z ComplexNumber
+		if _, ok := z.SetString(in); ok {
+			t.Fatalf("%q should not parse", in)
+		}
+		if err := z.UnmarshalText([]byte(in)); err != ErrInvalidEncoding {
+			t.Fatalf("expected ErrInvalidEncoding for %q", in)
+		}
+	}
+}
@@ -0,0 +1,538 @@
+package zz2
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+)
+
+// NbLimbs is the number of 64-bit limbs of a FixedInt.
+//
+// A FixedInt holds signed 319-bit integers. This leaves enough room for the
+// intermediate products of the half-GCD on 256-bit orders: the inputs are
+// ≈ 2^128 and the norms and x*ȳ products are ≈ 2^258.
+const NbLimbs = 5
+
+// MaxHalfGCDFixedBits is the maximum bit length of the components of the
+// operands of HalfGCDFixed.
+const MaxHalfGCDFixedBits = 150
+
+// ErrFixedOverflow is returned when a big.Int does not fit in a FixedInt.
+var ErrFixedOverflow = errors.New("zz2: integer does not fit in a FixedInt")
+
+// A FixedInt represents a signed integer in two's complement on NbLimbs
+// little-endian 64-bit limbs. Arithmetic is modulo 2^(64·NbLimbs) and does not
+// allocate.
+type FixedInt [NbLimbs]uint64
+
+// A FixedComplexNumber represents a fixed-precision Z[√−2] integer.
+//
+// It has the same API as ComplexNumber, except that results are returned by
+// value so that no operation allocates. It is correct as long as the
+// components of the operands of HalfGCDFixed are at most MaxHalfGCDFixedBits
+// long.
+type FixedComplexNumber struct {
+	A0, A1 FixedInt
+}
+
+// SetBigInt sets z to x, and returns z. It returns ErrFixedOverflow if x is
+// not in (-2^319, 2^319).
+func (z *FixedInt) SetBigInt(x *big.Int) (*FixedInt, error) {
+	if x.BitLen() > 64*NbLimbs-1 {
+		return z, ErrFixedOverflow
+	}
+	*z = FixedInt{}
+	for i, w := range x.Bits() {
+		pos := i * bits.UintSize
+		z[pos/64] |= uint64(w) << (pos % 64)
+	}
+	if x.Sign() < 0 {
+		z.neg(z)
+	}
+	return z, nil
+}
+
+// BigInt sets res to z, and returns res.
+func (z *FixedInt) BigInt(res *big.Int) *big.Int {
+	var abs FixedInt
+	abs.abs(z)
+	var limb big.Int
+	res.SetUint64(0)
+	for i := NbLimbs - 1; i >= 0; i-- {
+		res.Lsh(res, 64)
+		res.Or(res, limb.SetUint64(abs[i]))
+	}
+	if z.isNeg() {
+		res.Neg(res)
+	}
+	return res
+}
+
+// String implements Stringer interface for fancy printing
+func (z *FixedInt) String() string {
+	return z.BigInt(new(big.Int)).String()
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of z.
+func (z *FixedInt) Sign() int {
+	if z.isNeg() {
+		return -1
+	}
+	if *z == (FixedInt{}) {
+		return 0
+	}
+	return 1
+}
+
+// Cmp compares z and x and returns -1, 0 or +1 if z < x, z == x or z > x.
+func (z *FixedInt) Cmp(x *FixedInt) int {
+	zNeg, xNeg := z.isNeg(), x.isNeg()
+	if zNeg != xNeg {
+		if zNeg {
+			return -1
+		}
+		return 1
+	}
+	// same sign: two's complement order is the unsigned order
+	return ucmp(z, x)
+}
+
+func (z *FixedInt) isNeg() bool {
+	return z[NbLimbs-1]>>63 == 1
+}
+
+func (z *FixedInt) setInt64(x int64) *FixedInt {
+	ext := uint64(x >> 63) // sign extension
+	for i := range z {
+		z[i] = ext
+	}
+	z[0] = uint64(x)
+	return z
+}
+
+func (z *FixedInt) add(x, y *FixedInt) *FixedInt {
+	var c uint64
+	for i := 0; i < NbLimbs; i++ {
+		z[i], c = bits.Add64(x[i], y[i], c)
+	}
+	return z
+}
+
+func (z *FixedInt) sub(x, y *FixedInt) *FixedInt {
+	var b uint64
+	for i := 0; i < NbLimbs; i++ {
+		z[i], b = bits.Sub64(x[i], y[i], b)
+	}
+	return z
+}
+
+func (z *FixedInt) neg(x *FixedInt) *FixedInt {
+	var zero FixedInt
+	return z.sub(&zero, x)
+}
+
+func (z *FixedInt) abs(x *FixedInt) *FixedInt {
+	if x.isNeg() {
+		return z.neg(x)
+	}
+	*z = *x
+	return z
+}
+
+// mul sets z to x*y mod 2^(64·NbLimbs). The truncated product is the same
+// for signed and unsigned operands.
+func (z *FixedInt) mul(x, y *FixedInt) *FixedInt {
+	var res FixedInt
+	for i := 0; i < NbLimbs; i++ {
+		var carry uint64
+		for j := 0; i+j < NbLimbs; j++ {
+			hi, lo := bits.Mul64(x[i], y[j])
+			var c uint64
+			lo, c = bits.Add64(lo, res[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			res[i+j] = lo
+			carry = hi
+		}
+	}
+	*z = res
+	return z
+}
+
+// ucmp compares x and y as unsigned integers.
+func ucmp(x, y *FixedInt) int {
+	for i := NbLimbs - 1; i >= 0; i-- {
+		if x[i] != y[i] {
+			if x[i] < y[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// udiv sets q to ⌊u/v⌋ for unsigned u and v≠0 (Knuth's algorithm D).
+func udiv(q, u, v *FixedInt) {
+	n := NbLimbs
+	for n > 0 && v[n-1] == 0 {
+		n--
+	}
+	if n == 0 {
+		panic("division by zero")
+	}
+	m := NbLimbs
+	for m > 0 && u[m-1] == 0 {
+		m--
+	}
+	*q = FixedInt{}
+	if m < n {
+		return
+	}
+
+	if n == 1 {
+		var r uint64
+		for i := m - 1; i >= 0; i-- {
+			q[i], r = bits.Div64(r, u[i], v[0])
+		}
+		return
+	}
+
+	// D1: normalise so that the top limb of v has its high bit set.
+	s := uint(bits.LeadingZeros64(v[n-1]))
+	var vn [NbLimbs]uint64
+	var un [NbLimbs + 1]uint64
+	for i := n - 1; i > 0; i-- {
+		vn[i] = v[i]<<s | v[i-1]>>(63-s)>>1
+	}
+	vn[0] = v[0] << s
+	un[m] = u[m-1] >> (63 - s) >> 1
+	for i := m - 1; i > 0; i-- {
+		un[i] = u[i]<<s | u[i-1]>>(63-s)>>1
+	}
+	un[0] = u[0] << s
+
+	vn1, vn2 := vn[n-1], vn[n-2]
+	for j := m - n; j >= 0; j-- {
+		// D3: estimate q̂ from the top two limbs.
+		qhat := ^uint64(0)
+		if ujn := un[j+n]; ujn != vn1 {
+			var rhat uint64
+			qhat, rhat = bits.Div64(ujn, un[j+n-1], vn1)
+			x1, x2 := bits.Mul64(qhat, vn2)
+			for x1 > rhat || (x1 == rhat && x2 > un[j+n-2]) {
+				qhat--
+				prev := rhat
+				rhat += vn1
+				if rhat < prev {
+					break
+				}
+				x1, x2 = bits.Mul64(qhat, vn2)
+			}
+		}
+
+		// D4: multiply and subtract.
+		var mulCarry, borrow uint64
+		for i := 0; i < n; i++ {
+			hi, lo := bits.Mul64(qhat, vn[i])
+			var c uint64
+			lo, c = bits.Add64(lo, mulCarry, 0)
+			mulCarry = hi + c
+			un[j+i], borrow = bits.Sub64(un[j+i], lo, borrow)
+		}
+		un[j+n], borrow = bits.Sub64(un[j+n], mulCarry, borrow)
+
+		// D6: add back if q̂ was one too large.
+		if borrow != 0 {
+			qhat--
+			var c uint64
+			for i := 0; i < n; i++ {
+				un[j+i], c = bits.Add64(un[j+i], vn[i], c)
+			}
+			un[j+n] += c
+		}
+		q[j] = qhat
+	}
+}
+
+// roundNearestFixed sets z to ⌊(x + d/2) / d⌋ for *any* sign of x, d>0
+func roundNearestFixed(z, x, d *FixedInt) *FixedInt {
+	var half, num FixedInt
+	half = *d
+	for i := 0; i < NbLimbs-1; i++ {
+		half[i] = half[i]>>1 | half[i+1]<<63
+	}
+	half[NbLimbs-1] >>= 1
+	neg := x.isNeg()
+	num.abs(x)
+	num.add(&num, &half)
+	udiv(z, &num, d)
+	if neg {
+		z.neg(z)
+	}
+	return z
+}
+
+// sqrtFixed sets z to ⌊√x⌋ for x ≥ 0 (Newton iteration).
+func sqrtFixed(z, x *FixedInt) *FixedInt {
+	if x.Sign() == 0 {
+		*z = FixedInt{}
+		return z
+	}
+	bitLen := 0
+	for i := NbLimbs - 1; i >= 0; i-- {
+		if x[i] != 0 {
+			bitLen = 64*i + bits.Len64(x[i])
+			break
+		}
+	}
+	// start above the root: 2^⌈bitLen/2⌉ > √x
+	var y, t FixedInt
+	e := (bitLen + 1) / 2
+	y[e/64] = 1 << (e % 64)
+	for {
+		// t = (y + x/y) / 2
+		udiv(&t, x, &y)
+		t.add(&t, &y)
+		for i := 0; i < NbLimbs-1; i++ {
+			t[i] = t[i]>>1 | t[i+1]<<63
+		}
+		t[NbLimbs-1] >>= 1
+		if ucmp(&t, &y) >= 0 {
+			*z = y
+			return z
+		}
+		y = t
+	}
+}
+
+// SetComplexNumber sets z to x, and returns z. It returns ErrFixedOverflow if
+// a component of x does not fit in a FixedInt.
+func (z *FixedComplexNumber) SetComplexNumber(x *ComplexNumber) (*FixedComplexNumber, error) {
+	if _, err := z.A0.SetBigInt(x.A0); err != nil {
+		return z, err
+	}
+	if _, err := z.A1.SetBigInt(x.A1); err != nil {
+		return z, err
+	}
+	return z, nil
+}
+
+// ComplexNumber returns z as an arbitrary-precision ComplexNumber.
+func (z *FixedComplexNumber) ComplexNumber() *ComplexNumber {
+	return &ComplexNumber{
+		A0: z.A0.BigInt(new(big.Int)),
+		A1: z.A1.BigInt(new(big.Int)),
+	}
+}
+
+// String implements Stringer interface for fancy printing
+func (z *FixedComplexNumber) String() string {
+	return z.A0.String() + "+(" + z.A1.String() + "*j)"
+}
+
+// Equal returns true if z equals x, false otherwise
+func (z *FixedComplexNumber) Equal(x *FixedComplexNumber) bool {
+	return *z == *x
+}
+
+// Set sets z to x, and returns z.
+func (z *FixedComplexNumber) Set(x *FixedComplexNumber) *FixedComplexNumber {
+	*z = *x
+	return z
+}
+
+// SetZero sets z to 0, and returns z.
+func (z *FixedComplexNumber) SetZero() *FixedComplexNumber {
+	*z = FixedComplexNumber{}
+	return z
+}
+
+// SetOne sets z to 1, and returns z.
+func (z *FixedComplexNumber) SetOne() *FixedComplexNumber {
+	*z = FixedComplexNumber{}
+	z.A0[0] = 1
+	return z
+}
+
+// Neg sets z to the negative of x, and returns z.
+func (z *FixedComplexNumber) Neg(x *FixedComplexNumber) *FixedComplexNumber {
+	z.A0.neg(&x.A0)
+	z.A1.neg(&x.A1)
+	return z
+}
+
+// Conjugate sets z to the conjugate of x, and returns z.
+func (z *FixedComplexNumber) Conjugate(x *FixedComplexNumber) *FixedComplexNumber {
+	z.A0 = x.A0
+	z.A1.neg(&x.A1)
+	return z
+}
+
+// Add sets z to the sum of x and y, and returns z.
+func (z *FixedComplexNumber) Add(x, y *FixedComplexNumber) *FixedComplexNumber {
+	z.A0.add(&x.A0, &y.A0)
+	z.A1.add(&x.A1, &y.A1)
+	return z
+}
+
+// Sub sets z to the difference of x and y, and returns z.
+func (z *FixedComplexNumber) Sub(x, y *FixedComplexNumber) *FixedComplexNumber {
+	z.A0.sub(&x.A0, &y.A0)
+	z.A1.sub(&x.A1, &y.A1)
+	return z
+}
+
+// Mul sets z to the product of x and y, and returns z.
+//
+// Given that j²+2=0, the explicit formula is:
+//
+//	(x0+x1j)(y0+y1j) = (x0y0-2x1y1) + (x0y1+x1y0)j
+func (z *FixedComplexNumber) Mul(x, y *FixedComplexNumber) *FixedComplexNumber {
+	var t [3]FixedInt
+	var z0, z1 FixedInt
+	t[0].mul(&x.A0, &y.A0)
+	t[1].mul(&x.A1, &y.A1)
+	z0.sub(&t[0], &t[1])
+	z0.sub(&z0, &t[1])
+	t[0].mul(&x.A0, &y.A1)
+	t[2].mul(&x.A1, &y.A0)
+	z1.add(&t[0], &t[2])
+	z.A0 = z0
+	z.A1 = z1
+	return z
+}
+
+// Norm returns the norm of z.
+//
+// The explicit formula is:
+//
+//	N(x0+x1j) = x0² + 2x1²
+func (z *FixedComplexNumber) Norm() FixedInt {
+	var norm, temp FixedInt
+	norm.mul(&z.A0, &z.A0)
+	temp.mul(&z.A1, &z.A1)
+	norm.add(&norm, &temp)
+	norm.add(&norm, &temp)
+	return norm
+}
+
+// QuoRem sets z to the Euclidean quotient of x / y, r to the remainder,
+// and guarantees ‖r‖ < ‖y‖ (true Euclidean division in ℤ[j]).
+//
+// It computes the same quotient and remainder as ComplexNumber.QuoRem.
+func (z *FixedComplexNumber) QuoRem(x, y, r *FixedComplexNumber) (*FixedComplexNumber, *FixedComplexNumber) {
+
+	norm := y.Norm() // > 0  (Z[√−2] norm is always non-neg)
+	if norm.Sign() == 0 {
+		panic("division by zero")
+	}
+
+	// num = x * ȳ
+	var yConj, num, q, rem FixedComplexNumber
+	yConj.Conjugate(y)
+	num.Mul(x, &yConj)
+
+	// first guess by *symmetric* rounding of both coordinates
+	roundNearestFixed(&q.A0, &num.A0, &norm)
+	roundNearestFixed(&q.A1, &num.A1, &norm)
+
+	// r = x – q*y
+	rem.Mul(y, &q)
+	rem.Sub(x, &rem)
+
+	// If Euclidean inequality already holds we're done.
+	// Otherwise walk the neighbours until N(r) < N(y).
+	remNorm := rem.Norm()
+	if remNorm.Cmp(&norm) >= 0 {
+		best := q
+		var dir FixedComplexNumber
+		for _, d := range neighbours {
+			var candQ, candR FixedComplexNumber
+			dir.A0.setInt64(d[0])
+			dir.A1.setInt64(d[1])
+			candQ.Add(&q, &dir)
+			candR.Mul(y, &candQ)
+			candR.Sub(x, &candR)
+			if candNorm := candR.Norm(); candNorm.Cmp(&remNorm) < 0 {
+				best = candQ
+				rem = candR
+				remNorm = candNorm
+			}
+		}
+		q = best
+	}
+	*z = q
+	*r = rem
+	return z, r
+}
+
+// HalfGCDFixed returns the rational reconstruction of a, b.
+// This outputs w, v, u s.t. w = a*u + b*v.
+//
+// It computes the same values as HalfGCD without allocating.
+func HalfGCDFixed(a, b *FixedComplexNumber) [3]FixedComplexNumber {
+
+	var aRun, bRun, u, v, u_, v_, quotient, remainder, t, t1, t2 FixedComplexNumber
+	var sqrt FixedInt
+
+	aRun.Set(a)
+	bRun.Set(b)
+	u.SetOne()
+	v.SetZero()
+	u_.SetZero()
+	v_.SetOne()
+
+	// Z[√−2] integers form an Euclidean domain for the norm
+	aNorm := a.Norm()
+	sqrtFixed(&sqrt, &aNorm)
+	for {
+		bNorm := bRun.Norm()
+		if bNorm.Cmp(&sqrt) < 0 {
+			break
+		}
+		quotient.QuoRem(&aRun, &bRun, &remainder)
+		t.Mul(&u_, &quotient)
+		t1.Sub(&u, &t)
+		t.Mul(&v_, &quotient)
+		t2.Sub(&v, &t)
+		aRun.Set(&bRun)
+		u.Set(&u_)
+		v.Set(&v_)
+		bRun.Set(&remainder)
+		u_.Set(&t1)
+		v_.Set(&t2)
+	}
+
+	return [3]FixedComplexNumber{bRun, v_, u_}
+}
+
+// HalfGCDAuto returns HalfGCD(a, b). It runs the allocation-free HalfGCDFixed
+// when the components of a and b are at most MaxHalfGCDFixedBits long, and
+// falls back to the arbitrary-precision HalfGCD otherwise.
+func HalfGCDAuto(a, b *ComplexNumber) [3]*ComplexNumber {
+	if !fitsHalfGCDFixed(a, b) {
+		return HalfGCD(a, b)
+	}
+	var x, y FixedComplexNumber
+	x.SetComplexNumber(a)
+	y.SetComplexNumber(b)
+	res := HalfGCDFixed(&x, &y)
+	return [3]*ComplexNumber{
+		res[0].ComplexNumber(),
+		res[1].ComplexNumber(),
+		res[2].ComplexNumber(),
+	}
+}
+
+// fitsHalfGCDFixed returns true if the components of a and b are at most
+// MaxHalfGCDFixedBits long.
+func fitsHalfGCDFixed(a, b *ComplexNumber) bool {
+	for _, c := range []*big.Int{a.A0, a.A1, b.A0, b.A1} {
+		if c.BitLen() > MaxHalfGCDFixedBits {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,222 @@
+package zz2
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestFixedIntConversion(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genN := GenSignedNumber(64*NbLimbs - 1)
+
+	properties.Property("SetBigInt then BigInt should leave an integer invariant", prop.ForAll(
+		func(a *big.Int) bool {
+			var x FixedInt
+			if _, err := x.SetBigInt(a); err != nil {
+				return false
+			}
+			return x.BigInt(new(big.Int)).Cmp(a) == 0 && x.Sign() == a.Sign()
+		},
+		genN,
+	))
+
+	properties.Property("Cmp should match big.Int Cmp", prop.ForAll(
+		func(a, b *big.Int) bool {
+			var x, y FixedInt
+			x.SetBigInt(a)
+			y.SetBigInt(b)
+			return x.Cmp(&y) == a.Cmp(b)
+		},
+		genN,
+		genN,
+	))
+
+	properties.Property("udiv should match big.Int Quo", prop.ForAll(
+		func(a, b *big.Int) bool {
+			a, b = new(big.Int).Abs(a), new(big.Int).Abs(b)
+			if b.Sign() == 0 {
+				return true
+			}
+			var x, y, q FixedInt
+			x.SetBigInt(a)
+			y.SetBigInt(b)
+			udiv(&q, &x, &y)
+			return q.BigInt(new(big.Int)).Cmp(new(big.Int).Quo(a, b)) == 0
+		},
+		genN,
+		GenSignedNumber(boundSize),
+	))
+
+	properties.Property("sqrtFixed should match big.Int Sqrt", prop.ForAll(
+		func(a *big.Int) bool {
+			a = new(big.Int).Abs(a)
+			var x, s FixedInt
+			x.SetBigInt(a)
+			sqrtFixed(&s, &x)
+			return s.BigInt(new(big.Int)).Cmp(new(big.Int).Sqrt(a)) == 0
+		},
+		genN,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	var x FixedInt
+	tooBig := new(big.Int).Lsh(big.NewInt(1), 64*NbLimbs-1)
+	if _, err := x.SetBigInt(tooBig); err != ErrFixedOverflow {
+		t.Fatal("expected ErrFixedOverflow")
+	}
+}
+
+func TestFixedMatchesComplexNumber(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genE := GenSignedComplexNumber(boundSize)
+
+	properties.Property("Mul and Norm should match ComplexNumber", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			var x, y, z FixedComplexNumber
+			x.SetComplexNumber(a)
+			y.SetComplexNumber(b)
+			z.Mul(&x, &y)
+			var c ComplexNumber
+			c.Mul(a, b)
+			n := x.Norm()
+			return z.ComplexNumber().Equal(&c) && n.BigInt(new(big.Int)).Cmp(a.Norm()) == 0
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("QuoRem should match ComplexNumber", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			if b.A0.Sign() == 0 && b.A1.Sign() == 0 {
+				return true
+			}
+			var x, y, q, r FixedComplexNumber
+			x.SetComplexNumber(a)
+			y.SetComplexNumber(b)
+			q.QuoRem(&x, &y, &r)
+			var c, d ComplexNumber
+			c.QuoRem(a, b, &d)
+			return q.ComplexNumber().Equal(&c) && r.ComplexNumber().Equal(&d)
+		},
+		genE,
+		GenSignedComplexNumber(boundSize/2),
+	))
+
+	properties.Property("HalfGCDFixed should match HalfGCD", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			var x, y FixedComplexNumber
+			x.SetComplexNumber(a)
+			y.SetComplexNumber(b)
+			res := HalfGCDFixed(&x, &y)
+			expected := HalfGCD(a, b)
+			for i := range res {
+				if !res[i].ComplexNumber().Equal(expected[i]) {
+					return false
+				}
+			}
+			return true
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("HalfGCDAuto should match HalfGCD, also above MaxHalfGCDFixedBits", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			res := HalfGCDAuto(a, b)
+			expected := HalfGCD(a, b)
+			for i := range res {
+				if !res[i].Equal(expected[i]) {
+					return false
+				}
+			}
+			return true
+		},
+		GenSignedComplexNumber(boundSize),
+		GenSignedComplexNumber(MaxHalfGCDFixedBits+boundSize/2),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestHalfGCDFixedDoesNotAllocate(t *testing.T) {
+	var n, _ = new(big.Int).SetString("100000000000000000000000000000000", 16) // 2^128
+	a0, _ := rand.Int(rand.Reader, n)
+	a1, _ := rand.Int(rand.Reader, n)
+	c0, _ := rand.Int(rand.Reader, n)
+	c1, _ := rand.Int(rand.Reader, n)
+	var a, c FixedComplexNumber
+	a.SetComplexNumber(&ComplexNumber{A0: a0, A1: a1})
+	c.SetComplexNumber(&ComplexNumber{A0: c0, A1: c1})
+	allocs := testing.AllocsPerRun(10, func() {
+		benchResFixed = HalfGCDFixed(&a, &c)
+	})
+	if allocs != 0 {
+		t.Fatalf("HalfGCDFixed allocates %v times", allocs)
+	}
+}
+
+// GenSignedNumber generates a random signed integer
+func GenSignedNumber(boundSize int64) gopter.Gen {
+	return func(genParams *gopter.GenParameters) *gopter.GenResult {
+		var bound big.Int
+		bound.Exp(big.NewInt(2), big.NewInt(boundSize), nil)
+		elmt, _ := rand.Int(genParams.Rng, &bound)
+		if genParams.NextBool() {
+			elmt.Neg(elmt)
+		}
+		genResult := gopter.NewGenResult(elmt, gopter.NoShrinker)
+		return genResult
+	}
+}
+
+// GenSignedComplexNumber generates a random integer with signed components
+func GenSignedComplexNumber(boundSize int64) gopter.Gen {
+	return gopter.CombineGens(
+		GenSignedNumber(boundSize),
+		GenSignedNumber(boundSize),
+	).Map(func(values []interface{}) *ComplexNumber {
+		return &ComplexNumber{A0: values[0].(*big.Int), A1: values[1].(*big.Int)}
+	})
+}
+
+// bench
+var benchResFixed [3]FixedComplexNumber
+
+func BenchmarkHalfGCDFixed(b *testing.B) {
+	var n, _ = new(big.Int).SetString("100000000000000000000000000000000", 16) // 2^128
+	a0, _ := rand.Int(rand.Reader, n)
+	a1, _ := rand.Int(rand.Reader, n)
+	c0, _ := rand.Int(rand.Reader, n)
+	c1, _ := rand.Int(rand.Reader, n)
+	var a, c FixedComplexNumber
+	a.SetComplexNumber(&ComplexNumber{A0: a0, A1: a1})
+	c.SetComplexNumber(&ComplexNumber{A0: c0, A1: c1})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchResFixed = HalfGCDFixed(&a, &c)
+	}
+}
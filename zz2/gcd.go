@@ -0,0 +1,105 @@
+package zz2
+
+import (
+	"errors"
+)
+
+var (
+	// ErrNotDivisible is returned by Quo when the division is not exact.
+	ErrNotDivisible = errors.New("zz2: division is not exact")
+	// ErrDivisionByZero is returned by Quo when the divisor is zero.
+	ErrDivisionByZero = errors.New("zz2: division by zero")
+)
+
+// isZero returns true if z is 0.
+func (z *ComplexNumber) isZero() bool {
+	return z.A0.Sign() == 0 && z.A1.Sign() == 0
+}
+
+// Normalize sets z to the normalised associate of x, and returns z and the
+// unit u ∈ {±1} such that z = u*x.
+//
+// The unit group of Z[√−2] is {±1}, so the associates of x are x and -x. The
+// normalised one has A0 > 0, or A0 = 0 and A1 ≥ 0.
+func (z *ComplexNumber) Normalize(x *ComplexNumber) (*ComplexNumber, int) {
+	if x.A0.Sign() < 0 || (x.A0.Sign() == 0 && x.A1.Sign() < 0) {
+		return z.Neg(x), -1
+	}
+	return z.Set(x), 1
+}
+
+// ExtendedGCD returns the normalised gcd g of a and b, and Bézout
+// coefficients x, y such that g = a*x + b*y.
+//
+// It runs the Euclidean algorithm to completion, unlike HalfGCD which stops
+// halfway.
+func ExtendedGCD(a, b *ComplexNumber) (g, x, y *ComplexNumber) {
+	var aRun, bRun, u, v, u_, v_, quotient, remainder, t, t1, t2 ComplexNumber
+
+	aRun.Set(a)
+	bRun.Set(b)
+	u.SetOne()
+	v.SetZero()
+	u_.SetZero()
+	v_.SetOne()
+
+	// invariants: aRun = a*u + b*v and bRun = a*u_ + b*v_
+	for !bRun.isZero() {
+		quotient.QuoRem(&aRun, &bRun, &remainder)
+		t.Mul(&u_, &quotient)
+		t1.Sub(&u, &t)
+		t.Mul(&v_, &quotient)
+		t2.Sub(&v, &t)
+		aRun.Set(&bRun)
+		u.Set(&u_)
+		v.Set(&v_)
+		bRun.Set(&remainder)
+		u_.Set(&t1)
+		v_.Set(&t2)
+	}
+
+	g, x, y = new(ComplexNumber), new(ComplexNumber), new(ComplexNumber)
+	if _, unit := g.Normalize(&aRun); unit == -1 {
+		x.Neg(&u)
+		y.Neg(&v)
+	} else {
+		x.Set(&u)
+		y.Set(&v)
+	}
+	return g, x, y
+}
+
+// GCD returns the normalised gcd of a and b.
+func GCD(a, b *ComplexNumber) *ComplexNumber {
+	g, _, _ := ExtendedGCD(a, b)
+	return g
+}
+
+// Divides returns true if z divides x, false otherwise. Zero only divides
+// zero.
+func (z *ComplexNumber) Divides(x *ComplexNumber) bool {
+	if z.isZero() {
+		return x.isZero()
+	}
+	var q, r ComplexNumber
+	q.QuoRem(x, z, &r)
+	return r.isZero()
+}
+
+// Quo sets z to the exact quotient x / y, and returns z. It returns
+// ErrDivisionByZero if y is zero and ErrNotDivisible if y does not divide x,
+// and leaves z unchanged in both cases.
+//
+// Unlike QuoRem, which panics on a zero divisor, Quo does not panic, so that
+// a zero divisor can be told apart from a non-exact division.
+func (z *ComplexNumber) Quo(x, y *ComplexNumber) (*ComplexNumber, error) {
+	if y.isZero() {
+		return z, ErrDivisionByZero
+	}
+	var q, r ComplexNumber
+	q.QuoRem(x, y, &r)
+	if !r.isZero() {
+		return z, ErrNotDivisible
+	}
+	return z.Set(&q), nil
+}
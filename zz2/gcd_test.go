@@ -0,0 +1,127 @@
+package zz2
+
+import (
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestZZ2ExtendedGCD(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genE := GenSignedComplexNumber(boundSize)
+
+	properties.Property("ExtendedGCD should output Bézout coefficients", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			g, x, y := ExtendedGCD(a, b)
+			var c, d ComplexNumber
+			c.Mul(a, x)
+			d.Mul(b, y)
+			c.Add(&c, &d)
+			return c.Equal(g)
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("GCD should divide both operands and be normalised", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			g := GCD(a, b)
+			var n ComplexNumber
+			_, unit := n.Normalize(g)
+			return g.Divides(a) && g.Divides(b) && unit == 1
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("GCD of multiples should be a multiple of the common factor", prop.ForAll(
+		func(a, b, c *ComplexNumber) bool {
+			var ac, bc ComplexNumber
+			ac.Mul(a, c)
+			bc.Mul(b, c)
+			return c.Divides(GCD(&ac, &bc))
+		},
+		genE,
+		genE,
+		GenSignedComplexNumber(boundSize/2),
+	))
+
+	properties.Property("GCD should not depend on the associates", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			var na ComplexNumber
+			na.Neg(a)
+			return GCD(a, b).Equal(GCD(&na, b))
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("Quo should invert Mul", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			if b.isZero() {
+				return true
+			}
+			var c, q ComplexNumber
+			c.Mul(a, b)
+			if _, err := q.Quo(&c, b); err != nil {
+				return false
+			}
+			return q.Equal(a)
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("Quo should fail on non-exact division", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			var c, one, q ComplexNumber
+			one.SetOne()
+			// b*(a*b + 1) is not divisible by a*b (unless a*b is a unit)
+			c.Mul(a, b)
+			if c.Norm().BitLen() <= 1 {
+				return true
+			}
+			var d ComplexNumber
+			d.Add(&c, &one)
+			_, err := q.Quo(&d, &c)
+			return err == ErrNotDivisible && !c.Divides(&d)
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("Quo should fail on a zero divisor", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var q, zero, one ComplexNumber
+			zero.SetZero()
+			one.SetOne()
+			q.SetOne()
+			_, err := q.Quo(a, &zero)
+			return err == ErrDivisionByZero && q.Equal(&one)
+		},
+		genE,
+	))
+
+	// HalfGCD stops halfway through the Euclidean algorithm, so its
+	// remainder w = a*u + b*v is a multiple of the full gcd.
+	properties.Property("HalfGCD remainder should be a multiple of the GCD", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			res := HalfGCD(a, b)
+			return GCD(a, b).Divides(res[0])
+		},
+		genE,
+		genE,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
@@ -0,0 +1,104 @@
+package zz2
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	errNoSqrtMinusTwo   = errors.New("zz2: -2 is not a square modulo p")
+	errNoCornacchia     = errors.New("zz2: x² + 2y² = p has no solution")
+	errNotARootOfJ2     = errors.New("zz2: λ is not a root of j² + 2 modulo r")
+	errNoEigenvalueRoot = errors.New("zz2: no root of j² + 2 modulo r is an eigenvalue of the endomorphism")
+)
+
+// sqrtMinusTwo returns a square root of -2 modulo the odd prime p.
+func sqrtMinusTwo(p *big.Int) (*big.Int, error) {
+	minusTwo := new(big.Int).Sub(p, big.NewInt(2))
+	root := new(big.Int).ModSqrt(minusTwo, p)
+	if root == nil {
+		return nil, errNoSqrtMinusTwo
+	}
+	return root, nil
+}
+
+// Cornacchia returns x, y ≥ 0 such that x² + 2y² = p for the odd prime p.
+//
+// It runs the Euclidean algorithm on p and a square root r0 of -2 modulo p
+// until the remainder is below √p; the remainder is x. It returns an error if
+// -2 is not a square modulo p, in which case p is inert in Z[√−2].
+func Cornacchia(p *big.Int) (x, y *big.Int, err error) {
+	r0, err := sqrtMinusTwo(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	// without loss of generality r0 ≤ p/2
+	half := new(big.Int).Rsh(p, 1)
+	if r0.Cmp(half) > 0 {
+		r0.Sub(p, r0)
+	}
+
+	var a, b, sqrt big.Int
+	a.Set(p)
+	b.Set(r0)
+	sqrt.Sqrt(p)
+	for b.Cmp(&sqrt) > 0 {
+		a.Mod(&a, &b)
+		a, b = b, a
+	}
+
+	// y² = (p - x²) / 2
+	var rem big.Int
+	y = new(big.Int).Mul(&b, &b)
+	y.Sub(p, y)
+	y.QuoRem(y, big.NewInt(2), &rem)
+	if rem.Sign() != 0 {
+		return nil, nil, errNoCornacchia
+	}
+	root := new(big.Int).Sqrt(y)
+	if new(big.Int).Mul(root, root).Cmp(y) != 0 {
+		return nil, nil, errNoCornacchia
+	}
+	return new(big.Int).Set(&b), root, nil
+}
+
+// PrimeIdealGenerator returns π = x + yj with N(π) = r that generates the
+// prime ideal (r, j - λ) above r, i.e. such that x + yλ = 0 mod r.
+//
+// λ must be a root of j² + 2 modulo the prime r.
+func PrimeIdealGenerator(r, lambda *big.Int) (*ComplexNumber, error) {
+	var check big.Int
+	check.Mul(lambda, lambda).Add(&check, big.NewInt(2)).Mod(&check, r)
+	if check.Sign() != 0 {
+		return nil, errNotARootOfJ2
+	}
+
+	x, y, err := Cornacchia(r)
+	if err != nil {
+		return nil, err
+	}
+	// x + yj and its conjugate x - yj generate the two prime ideals above r,
+	// one for each root ±λ.
+	check.Mul(y, lambda).Add(&check, x).Mod(&check, r)
+	if check.Sign() != 0 {
+		y.Neg(y)
+	}
+	return &ComplexNumber{A0: x, A1: y}, nil
+}
+
+// Lambda returns the root λ of j² + 2 modulo the prime r that is the
+// eigenvalue of the endomorphism on the r-torsion, i.e. the root for which
+// isEigenvalue returns true. Typically isEigenvalue checks that φ(P) = [λ]P
+// for a point P of order r.
+func Lambda(r *big.Int, isEigenvalue func(lambda *big.Int) bool) (*big.Int, error) {
+	root, err := sqrtMinusTwo(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, lambda := range []*big.Int{root, new(big.Int).Sub(r, root)} {
+		if isEigenvalue(lambda) {
+			return lambda, nil
+		}
+	}
+	return nil, errNoEigenvalueRoot
+}
@@ -0,0 +1,128 @@
+package zz2
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/bandersnatch"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+// bandersnatchLambda is the constant pasted in the circuits hints.
+const bandersnatchLambda = "8913659658109529928382530854484400854125314752504019737736543920008458395397"
+
+func TestCornacchia(t *testing.T) {
+	t.Parallel()
+	for _, p := range []int64{3, 11, 17, 19, 41, 43, 59, 67, 73, 83, 89, 97} {
+		x, y, err := Cornacchia(big.NewInt(p))
+		if err != nil {
+			t.Fatalf("p=%d: %v", p, err)
+		}
+		if x.Int64()*x.Int64()+2*y.Int64()*y.Int64() != p {
+			t.Fatalf("p=%d: %s² + 2·%s² != p", p, x, y)
+		}
+	}
+	// p = 5, 7 mod 8 are inert in Z[√−2]
+	for _, p := range []int64{5, 7, 13, 23, 29, 31} {
+		if _, _, err := Cornacchia(big.NewInt(p)); err == nil {
+			t.Fatalf("p=%d: expected an error", p)
+		}
+	}
+
+	order := bandersnatch.GetEdwardsCurve().Order
+	x, y, err := Cornacchia(&order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pi := ComplexNumber{A0: x, A1: y}
+	if pi.Norm().Cmp(&order) != 0 {
+		t.Fatal("N(π) != r")
+	}
+}
+
+func TestLambdaBandersnatch(t *testing.T) {
+	t.Parallel()
+	curve := bandersnatch.GetEdwardsCurve()
+
+	// φ(x,y) = (f/xy, g/h) with f = c1(1-y²), g = c0(y²+c0), h = y²-c0
+	var c0, c1 fr.Element
+	c0.SetString("37446463827641770816307242315180085052603635617490163568005256780843403514036")
+	c1.SetString("49199877423542878313146170939139662862850515542392585932876811575731455068989")
+	var phi bandersnatch.PointAffine
+	{
+		var one, xy, yy, f, g, h fr.Element
+		one.SetOne()
+		P := curve.Base
+		xy.Mul(&P.X, &P.Y)
+		yy.Square(&P.Y)
+		f.Sub(&one, &yy).Mul(&f, &c1)
+		g.Add(&yy, &c0).Mul(&g, &c0)
+		h.Sub(&yy, &c0)
+		phi.X.Div(&f, &xy)
+		phi.Y.Div(&g, &h)
+	}
+
+	lambda, err := Lambda(&curve.Order, func(lambda *big.Int) bool {
+		var lP bandersnatch.PointAffine
+		lP.ScalarMultiplication(&curve.Base, lambda)
+		return lP.Equal(&phi)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lambda.String() != bandersnatchLambda {
+		t.Fatalf("expected λ = %s, got %s", bandersnatchLambda, lambda)
+	}
+
+	if _, err := Lambda(&curve.Order, func(*big.Int) bool { return false }); err == nil {
+		t.Fatal("expected an error when no root is an eigenvalue")
+	}
+}
+
+func TestPrimeIdealGenerator(t *testing.T) {
+	t.Parallel()
+	order := bandersnatch.GetEdwardsCurve().Order
+	lambda, _ := new(big.Int).SetString(bandersnatchLambda, 10)
+
+	pi, err := PrimeIdealGenerator(&order, lambda)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pi.Norm().Cmp(&order) != 0 {
+		t.Fatal("N(π) != r")
+	}
+	var check big.Int
+	check.Mul(pi.A1, lambda).Add(&check, pi.A0).Mod(&check, &order)
+	if check.Sign() != 0 {
+		t.Fatal("π is not in the ideal (r, j - λ)")
+	}
+
+	// the other root gives the conjugate ideal
+	minusLambda := new(big.Int).Sub(&order, lambda)
+	piConj, err := PrimeIdealGenerator(&order, minusLambda)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conj ComplexNumber
+	if !conj.Conjugate(pi).Equal(piConj) {
+		t.Fatal("expected the conjugate generator for -λ")
+	}
+
+	// the generator is short, so it decomposes scalars as well as the GLV
+	// lattice basis.
+	bound := HalfGCDBound(&order)
+	for i := 0; i < nbFuzzShort; i++ {
+		// N(s) = s0² + 2·s1² < r as HalfGCDBound requires
+		s0, _ := rand.Int(rand.Reader, new(big.Int).Sqrt(new(big.Int).Rsh(&order, 1)))
+		s1, _ := rand.Int(rand.Reader, new(big.Int).Sqrt(new(big.Int).Rsh(&order, 2)))
+		s := ComplexNumber{A0: s0, A1: s1}
+		if _, err := HalfGCDBounded(pi, &s, bound); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := PrimeIdealGenerator(&order, big.NewInt(3)); err == nil {
+		t.Fatal("expected an error for a λ that is not a root of j² + 2")
+	}
+}
@@ -0,0 +1,33 @@
+package zz2
+
+import (
+	"math/big"
+)
+
+// Mod sets z to the remainder of x modulo the principal ideal (π), and
+// returns z. The remainder is the one of QuoRem, so that N(z) < N(π).
+func (z *ComplexNumber) Mod(x, pi *ComplexNumber) *ComplexNumber {
+	var q, r ComplexNumber
+	q.QuoRem(x, pi, &r)
+	return z.Set(&r)
+}
+
+// Eval returns the image of z in F_r by the ring morphism j ↦ λ, i.e.
+// A0 + A1·λ mod r. It is well defined on Z[√−2]/(π) when π generates the
+// prime ideal (r, j - λ).
+func (z *ComplexNumber) Eval(lambda, r *big.Int) *big.Int {
+	res := new(big.Int).Mul(z.A1, lambda)
+	res.Add(res, z.A0)
+	return res.Mod(res, r)
+}
+
+// Lift sets z to the short representative of the scalar s modulo π, and
+// returns z. π must generate the prime ideal (r, j - λ) so that
+// z.Eval(λ, r) = s mod r and N(z) < r, i.e. |z.A0| < √r and |z.A1| < √(r/2).
+//
+// This is the scalar decomposition s = s1 + λ·s2 mod r of the GLV method with
+// s1 = z.A0 and s2 = z.A1.
+func (z *ComplexNumber) Lift(s *big.Int, pi *ComplexNumber) *ComplexNumber {
+	x := ComplexNumber{A0: new(big.Int).Set(s), A1: new(big.Int)}
+	return z.Mod(&x, pi)
+}
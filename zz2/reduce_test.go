@@ -0,0 +1,97 @@
+package zz2
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/bandersnatch"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestZZ2Reduction(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	order := bandersnatch.GetEdwardsCurve().Order
+	lambda, _ := new(big.Int).SetString(bandersnatchLambda, 10)
+	pi, err := PrimeIdealGenerator(&order, lambda)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	genE := GenSignedComplexNumber(2 * boundSize)
+	genS := GenNumber(int64(order.BitLen()))
+
+	properties.Property("π should map to zero", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b ComplexNumber
+			b.Mul(a, pi)
+			return pi.Eval(lambda, &order).Sign() == 0 && b.Eval(lambda, &order).Sign() == 0
+		},
+		genE,
+	))
+
+	properties.Property("Eval should be a ring morphism", prop.ForAll(
+		func(a, b *ComplexNumber) bool {
+			var c, d ComplexNumber
+			c.Mul(a, b)
+			d.Add(a, b)
+			mul := new(big.Int).Mul(a.Eval(lambda, &order), b.Eval(lambda, &order))
+			add := new(big.Int).Add(a.Eval(lambda, &order), b.Eval(lambda, &order))
+			return c.Eval(lambda, &order).Cmp(mul.Mod(mul, &order)) == 0 &&
+				d.Eval(lambda, &order).Cmp(add.Mod(add, &order)) == 0
+		},
+		genE,
+		genE,
+	))
+
+	properties.Property("Mod should preserve the image in F_r and be short", prop.ForAll(
+		func(a *ComplexNumber) bool {
+			var b ComplexNumber
+			b.Mod(a, pi)
+			return b.Eval(lambda, &order).Cmp(a.Eval(lambda, &order)) == 0 &&
+				b.Norm().Cmp(&order) < 0
+		},
+		genE,
+	))
+
+	properties.Property("Lift should split the scalar into short halves", prop.ForAll(
+		func(s *big.Int) bool {
+			var z ComplexNumber
+			z.Lift(s, pi)
+			sqrt := new(big.Int).Sqrt(&order)
+			return z.Eval(lambda, &order).Cmp(new(big.Int).Mod(s, &order)) == 0 &&
+				z.A0.CmpAbs(sqrt) <= 0 && z.A1.CmpAbs(sqrt) <= 0
+		},
+		genS,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	// the full decomposition pipeline of the circuits hints
+	bound := HalfGCDBound(&order)
+	for i := 0; i < nbFuzzShort; i++ {
+		s, _ := rand.Int(rand.Reader, &order)
+		var sz ComplexNumber
+		sz.Lift(s, pi).Neg(&sz)
+		res, err := HalfGCDBounded(pi, &sz, bound)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// u1 + λ*u2 + s*(v1 + λ*v2) == 0 mod r
+		lhs := new(big.Int).Mul(res[1].Eval(lambda, &order), s)
+		lhs.Add(lhs, res[0].Eval(lambda, &order)).Mod(lhs, &order)
+		if lhs.Sign() != 0 {
+			t.Fatal("u1 + λ*u2 + s*(v1 + λ*v2) != 0 mod r")
+		}
+	}
+}
@@ -0,0 +1,12 @@
+// Package zzd provides arithmetic in the Euclidean imaginary quadratic orders.
+//
+// For d ∈ {1, 2, 3, 7, 11} the ring of integers of Q(√−d) is a Euclidean
+// domain for the norm. These rings are of the form Z[ω] where
+//
+//   - ω = √−d and ω²+d=0 for d ∈ {1, 2} (Gaussian integers, Z[√−2]),
+//   - ω = (1+√−d)/2 and ω²−ω+(1+d)/4=0 for d ∈ {3, 7, 11} (Eisenstein
+//     integers for d=3).
+//
+// In both cases ω²=tω−n where t and n are the trace and the norm of ω, and an
+// element is written z = a + bω with a and b integers.
+package zzd
@@ -0,0 +1,327 @@
+package zzd
+
+import (
+	"errors"
+	"math/big"
+)
+
+// A Ring represents the Euclidean imaginary quadratic order Z[ω] of Q(√−d).
+type Ring struct {
+	d int64
+	// ω² = t·ω − n
+	t, n int64
+}
+
+// NewRing returns the ring of integers of Q(√−d). It returns an error if the
+// ring is not Euclidean for the norm, i.e. if d ∉ {1, 2, 3, 7, 11}.
+func NewRing(d int64) (*Ring, error) {
+	switch d {
+	case 1, 2:
+		return &Ring{d: d, t: 0, n: d}, nil
+	case 3, 7, 11:
+		return &Ring{d: d, t: 1, n: (1 + d) / 4}, nil
+	default:
+		return nil, errors.New("zzd: Z[ω] is not norm-Euclidean for this d")
+	}
+}
+
+// D returns the square-free integer d such that the ring is the ring of
+// integers of Q(√−d).
+func (r *Ring) D() int64 {
+	return r.d
+}
+
+// NewElement returns the element a0 + a1·ω of the ring.
+func (r *Ring) NewElement(a0, a1 *big.Int) *Element {
+	return &Element{
+		A0:   new(big.Int).Set(a0),
+		A1:   new(big.Int).Set(a1),
+		ring: r,
+	}
+}
+
+// An Element represents an arbitrary-precision Z[ω] integer.
+type Element struct {
+	A0, A1 *big.Int
+	ring   *Ring
+}
+
+// ringOf returns the ring x and y belong to. Zero and one are the same in
+// every ring so an element that is not attached to a ring yet adopts the ring
+// of the other operand.
+func ringOf(x, y *Element) *Ring {
+	switch {
+	case x.ring == nil:
+		return y.ring
+	case y.ring == nil || x.ring.d == y.ring.d:
+		return x.ring
+	default:
+		panic("zzd: operands belong to different rings")
+	}
+}
+
+// mustRing returns the ring of z and panics if z is not attached to one.
+func (z *Element) mustRing() *Ring {
+	if z.ring == nil {
+		panic("zzd: element is not attached to a ring")
+	}
+	return z.ring
+}
+
+// roundNearest returns ⌊(z + d/2) / d⌋ for *any* sign of z, d>0
+func roundNearest(z, d *big.Int) *big.Int {
+	num := new(big.Int).Lsh(z, 1)
+	num.Add(num, d)
+	den := new(big.Int).Lsh(d, 1)
+	// d > 0 so Euclidean division is the floor division.
+	return num.Div(num, den)
+}
+
+func (z *Element) init() {
+	if z.A0 == nil {
+		z.A0 = new(big.Int)
+	}
+	if z.A1 == nil {
+		z.A1 = new(big.Int)
+	}
+}
+
+// Ring returns the ring z belongs to, or nil if z is not attached to a ring.
+func (z *Element) Ring() *Ring {
+	return z.ring
+}
+
+// String implements Stringer interface for fancy printing
+func (z *Element) String() string {
+	if z.ring != nil && z.ring.t == 1 {
+		return z.A0.String() + "+(" + z.A1.String() + "*ω)"
+	}
+	return z.A0.String() + "+(" + z.A1.String() + "*j)"
+}
+
+// Equal returns true if z equals x, false otherwise. Elements of different
+// rings are never equal, even with the same coordinates. As for the
+// arithmetic, an element that is not attached to a ring is compared by its
+// coordinates only.
+func (z *Element) Equal(x *Element) bool {
+	if z.ring != nil && x.ring != nil && z.ring.d != x.ring.d {
+		return false
+	}
+	return z.A0.Cmp(x.A0) == 0 && z.A1.Cmp(x.A1) == 0
+}
+
+// Set sets z to x, and returns z.
+func (z *Element) Set(x *Element) *Element {
+	z.init()
+	z.A0.Set(x.A0)
+	z.A1.Set(x.A1)
+	z.ring = x.ring
+	return z
+}
+
+// SetZero sets z to 0, and returns z.
+func (z *Element) SetZero() *Element {
+	z.A0 = big.NewInt(0)
+	z.A1 = big.NewInt(0)
+	return z
+}
+
+// SetOne sets z to 1, and returns z.
+func (z *Element) SetOne() *Element {
+	z.A0 = big.NewInt(1)
+	z.A1 = big.NewInt(0)
+	return z
+}
+
+// Neg sets z to the negative of x, and returns z.
+func (z *Element) Neg(x *Element) *Element {
+	z.init()
+	z.A0.Neg(x.A0)
+	z.A1.Neg(x.A1)
+	z.ring = x.ring
+	return z
+}
+
+// Conjugate sets z to the conjugate of x, and returns z.
+//
+// The conjugate of ω is t−ω, so the explicit formula is:
+//
+//	conj(x0+x1ω) = (x0+t·x1) - x1ω
+func (z *Element) Conjugate(x *Element) *Element {
+	ring := x.mustRing()
+	z.init()
+	var t big.Int
+	t.Mul(x.A1, big.NewInt(ring.t))
+	z.A0.Add(x.A0, &t)
+	z.A1.Neg(x.A1)
+	z.ring = ring
+	return z
+}
+
+// Add sets z to the sum of x and y, and returns z.
+func (z *Element) Add(x, y *Element) *Element {
+	ring := ringOf(x, y)
+	z.init()
+	z.A0.Add(x.A0, y.A0)
+	z.A1.Add(x.A1, y.A1)
+	z.ring = ring
+	return z
+}
+
+// Sub sets z to the difference of x and y, and returns z.
+func (z *Element) Sub(x, y *Element) *Element {
+	ring := ringOf(x, y)
+	z.init()
+	z.A0.Sub(x.A0, y.A0)
+	z.A1.Sub(x.A1, y.A1)
+	z.ring = ring
+	return z
+}
+
+// Mul sets z to the product of x and y, and returns z.
+//
+// Given that ω²=tω−n, the explicit formula is:
+//
+//	(x0+x1ω)(y0+y1ω) = (x0y0-n·x1y1) + (x0y1+x1y0+t·x1y1)ω
+func (z *Element) Mul(x, y *Element) *Element {
+	ring := ringOf(x, y)
+	if ring == nil {
+		panic("zzd: element is not attached to a ring")
+	}
+	z.init()
+	var t [3]big.Int
+	var z0, z1 big.Int
+	t[0].Mul(x.A0, y.A0)
+	t[1].Mul(x.A1, y.A1)
+	t[2].Mul(&t[1], big.NewInt(ring.n))
+	z0.Sub(&t[0], &t[2])
+	t[2].Mul(&t[1], big.NewInt(ring.t))
+	t[0].Mul(x.A0, y.A1)
+	z1.Add(&t[0], &t[2])
+	t[0].Mul(x.A1, y.A0)
+	z1.Add(&z1, &t[0])
+	z.A0.Set(&z0)
+	z.A1.Set(&z1)
+	z.ring = ring
+	return z
+}
+
+// Norm returns the norm of z.
+//
+// The explicit formula is:
+//
+//	N(x0+x1ω) = x0² + t·x0x1 + n·x1²
+func (z *Element) Norm() *big.Int {
+	ring := z.mustRing()
+	norm := new(big.Int)
+	temp := new(big.Int)
+	norm.Mul(z.A0, z.A0)
+	temp.Mul(z.A0, z.A1)
+	temp.Mul(temp, big.NewInt(ring.t))
+	norm.Add(norm, temp)
+	temp.Mul(z.A1, z.A1)
+	temp.Mul(temp, big.NewInt(ring.n))
+	norm.Add(norm, temp)
+	return norm
+}
+
+// QuoRem sets z to the Euclidean quotient of x / y, r to the remainder,
+// and guarantees ‖r‖ < ‖y‖ (true Euclidean division in Z[ω]).
+func (z *Element) QuoRem(x, y, r *Element) (*Element, *Element) {
+	ring := ringOf(x, y)
+	if ring == nil {
+		panic("zzd: element is not attached to a ring")
+	}
+	if x.ring == nil {
+		x = new(Element).Set(x)
+		x.ring = ring
+	}
+	if y.ring == nil {
+		y = new(Element).Set(y)
+		y.ring = ring
+	}
+
+	norm := y.Norm() // > 0 (Z[ω] norm is always non-neg)
+	if norm.Sign() == 0 {
+		panic("division by zero")
+	}
+
+	// num = x * ȳ   (ȳ computed in a fresh variable → y unchanged)
+	// so that x/y = num/N(y) = c0 + c1ω.
+	var yConj, num Element
+	yConj.Conjugate(y)
+	num.Mul(x, &yConj)
+
+	// The real part of (c0-q0) + (c1-q1)ω is (c0-q0) + t(c1-q1)/2 and its
+	// imaginary part only depends on q1. For q1 ∈ {⌊c1⌋, ⌈c1⌉} the best q0 is
+	//
+	//	q0 = round(c0 + t(c1-q1)/2) = round((2num0 + t(num1-q1·N)) / 2N)
+	//
+	// and the best of the two candidates satisfies N(r) < N(y) for all the
+	// Euclidean d.
+	var bestQ, bestR Element
+	var bestN *big.Int
+	floor := new(big.Int).Div(num.A1, norm)
+	for i := int64(0); i < 2; i++ {
+		var candQ, candR Element
+		candQ.ring = ring
+		candQ.A1 = new(big.Int).Add(floor, big.NewInt(i))
+		q0 := new(big.Int).Mul(candQ.A1, norm)
+		q0.Sub(num.A1, q0)
+		q0.Mul(q0, big.NewInt(ring.t))
+		q0.Add(q0, new(big.Int).Lsh(num.A0, 1))
+		candQ.A0 = roundNearest(q0, new(big.Int).Lsh(norm, 1))
+
+		// r = x – q*y
+		candR.Mul(y, &candQ)
+		candR.Sub(x, &candR)
+		candN := candR.Norm()
+		if bestN == nil || candN.Cmp(bestN) < 0 {
+			bestQ.Set(&candQ)
+			bestR.Set(&candR)
+			bestN = candN
+		}
+	}
+	z.Set(&bestQ)
+	r.Set(&bestR)
+	return z, r
+}
+
+// HalfGCD returns the rational reconstruction of a, b.
+// This outputs w, v, u s.t. w = a*u + b*v.
+func HalfGCD(a, b *Element) [3]*Element {
+	ring := ringOf(a, b)
+	if ring == nil {
+		panic("zzd: element is not attached to a ring")
+	}
+
+	var aRun, bRun, u, v, u_, v_, quotient, remainder, t, t1, t2 Element
+	var sqrt big.Int
+
+	aRun.Set(a)
+	bRun.Set(b)
+	aRun.ring, bRun.ring = ring, ring
+	u.SetOne()
+	v.SetZero()
+	u_.SetZero()
+	v_.SetOne()
+
+	// Z[ω] integers form an Euclidean domain for the norm
+	sqrt.Sqrt(aRun.Norm())
+	for bRun.Norm().Cmp(&sqrt) >= 0 {
+		quotient.QuoRem(&aRun, &bRun, &remainder)
+		t.Mul(&u_, &quotient)
+		t1.Sub(&u, &t)
+		t.Mul(&v_, &quotient)
+		t2.Sub(&v, &t)
+		aRun.Set(&bRun)
+		u.Set(&u_)
+		v.Set(&v_)
+		bRun.Set(&remainder)
+		u_.Set(&t1)
+		v_.Set(&t2)
+	}
+	bRun.ring, v_.ring, u_.ring = ring, ring, ring
+
+	return [3]*Element{&bRun, &v_, &u_}
+}
@@ -0,0 +1,354 @@
+package zzd
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+	"github.com/yelhousni/jubjub-vs-bandersnatch/zz2"
+)
+
+const (
+	nbFuzzShort = 10
+	nbFuzz      = 50
+	boundSize   = 128
+)
+
+var euclideanD = []int64{1, 2, 3, 7, 11}
+
+func TestNewRing(t *testing.T) {
+	for _, d := range euclideanD {
+		if _, err := NewRing(d); err != nil {
+			t.Fatalf("d=%d: %v", d, err)
+		}
+	}
+	for _, d := range []int64{0, 5, 6, 15, 19} {
+		if _, err := NewRing(d); err == nil {
+			t.Fatalf("d=%d: expected an error", d)
+		}
+	}
+}
+
+func TestEqualRings(t *testing.T) {
+	zi, _ := NewRing(1)
+	zsqrt2, _ := NewRing(2)
+	a := zi.NewElement(big.NewInt(3), big.NewInt(-5))
+	if !a.Equal(zi.NewElement(big.NewInt(3), big.NewInt(-5))) {
+		t.Fatal("equal elements of Z[√−1] compare different")
+	}
+	// 3 - 5i and 3 - 5√−2 have the same coordinates
+	if a.Equal(zsqrt2.NewElement(big.NewInt(3), big.NewInt(-5))) {
+		t.Fatal("elements of Z[√−1] and Z[√−2] compare equal")
+	}
+	// an element that is not attached to a ring yet adopts the other ring
+	var one Element
+	one.SetOne()
+	if !one.Equal(zsqrt2.NewElement(big.NewInt(1), big.NewInt(0))) || !zi.NewElement(big.NewInt(1), big.NewInt(0)).Equal(&one) {
+		t.Fatal("1 compares different to the one of a ring")
+	}
+}
+
+func TestZZDReceiverIsOperand(t *testing.T) {
+
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	for _, d := range euclideanD {
+		ring, _ := NewRing(d)
+		properties := gopter.NewProperties(parameters)
+
+		genE := GenElement(ring, boundSize)
+
+		properties.Property("Having the receiver as operand (mul) should output the same result", prop.ForAll(
+			func(a, b *Element) bool {
+				var c, e Element
+				e.Set(a)
+				c.Mul(a, b)
+				a.Mul(a, b)
+				b.Mul(&e, b)
+				return a.Equal(b) && a.Equal(&c) && b.Equal(&c)
+			},
+			genE,
+			genE,
+		))
+
+		properties.Property("Having the receiver as operand (conjugate) should output the same result", prop.ForAll(
+			func(a *Element) bool {
+				var b Element
+				b.Conjugate(a)
+				a.Conjugate(a)
+				return a.Equal(&b)
+			},
+			genE,
+		))
+
+		properties.TestingRun(t, gopter.ConsoleReporter(false))
+	}
+}
+
+func TestZZDArithmetic(t *testing.T) {
+
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	for _, d := range euclideanD {
+		ring, _ := NewRing(d)
+		properties := gopter.NewProperties(parameters)
+
+		genE := GenElement(ring, boundSize)
+
+		properties.Property("conj twice should leave an element invariant", prop.ForAll(
+			func(a *Element) bool {
+				var b Element
+				b.Conjugate(a).Conjugate(&b)
+				return a.Equal(&b)
+			},
+			genE,
+		))
+
+		properties.Property("mul by one should leave element invariant", prop.ForAll(
+			func(a *Element) bool {
+				var b, one Element
+				one.SetOne()
+				b.Mul(a, &one)
+				return a.Equal(&b)
+			},
+			genE,
+		))
+
+		properties.Property("mul should be commutative", prop.ForAll(
+			func(a, b *Element) bool {
+				var c, e Element
+				c.Mul(a, b)
+				e.Mul(b, a)
+				return c.Equal(&e)
+			},
+			genE,
+			genE,
+		))
+
+		properties.Property("mul should be assiocative", prop.ForAll(
+			func(a, b, c *Element) bool {
+				var e, f Element
+				e.Mul(a, b).Mul(&e, c)
+				f.Mul(c, b).Mul(&f, a)
+				return e.Equal(&f)
+			},
+			genE,
+			genE,
+			genE,
+		))
+
+		properties.Property("mul should distribute over add", prop.ForAll(
+			func(a, b, c *Element) bool {
+				var e, f, g Element
+				e.Add(a, b).Mul(&e, c)
+				f.Mul(a, c)
+				g.Mul(b, c)
+				f.Add(&f, &g)
+				return e.Equal(&f)
+			},
+			genE,
+			genE,
+			genE,
+		))
+
+		properties.Property("z * conj(z) should be the norm of z", prop.ForAll(
+			func(a *Element) bool {
+				var b Element
+				b.Conjugate(a).Mul(&b, a)
+				return b.A1.Sign() == 0 && b.A0.Cmp(a.Norm()) == 0
+			},
+			genE,
+		))
+
+		properties.Property("norm should be multiplicative", prop.ForAll(
+			func(a, b *Element) bool {
+				var c Element
+				c.Mul(a, b)
+				n := new(big.Int).Mul(a.Norm(), b.Norm())
+				return c.Norm().Cmp(n) == 0
+			},
+			genE,
+			genE,
+		))
+
+		properties.Property("norm should always be positive", prop.ForAll(
+			func(a *Element) bool {
+				return a.Norm().Sign() >= 0
+			},
+			genE,
+		))
+
+		properties.TestingRun(t, gopter.ConsoleReporter(false))
+	}
+}
+
+func TestZZDQuoRem(t *testing.T) {
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	for _, d := range euclideanD {
+		ring, _ := NewRing(d)
+		properties := gopter.NewProperties(parameters)
+		genE := GenElement(ring, boundSize)
+		genS := GenElement(ring, boundSize/2)
+
+		properties.Property("QuoRem should be correct", prop.ForAll(
+			func(a, b *Element) bool {
+				var z, rem Element
+				z.QuoRem(a, b, &rem)
+				var res Element
+				res.Mul(b, &z)
+				res.Add(&res, &rem)
+				return res.Equal(a)
+			},
+			genE,
+			genS,
+		))
+
+		properties.Property("QuoRem remainder should be smaller than divisor", prop.ForAll(
+			func(a, b *Element) bool {
+				var z, rem Element
+				z.QuoRem(a, b, &rem)
+				return rem.Norm().Cmp(b.Norm()) == -1
+			},
+			genE,
+			genS,
+		))
+
+		properties.TestingRun(t, gopter.ConsoleReporter(false))
+	}
+}
+
+func TestZZDHalfGCD(t *testing.T) {
+
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	for _, d := range euclideanD {
+		ring, _ := NewRing(d)
+		properties := gopter.NewProperties(parameters)
+
+		genE := GenElement(ring, boundSize)
+
+		properties.Property("half-GCD", prop.ForAll(
+			func(a, b *Element) bool {
+				res := HalfGCD(a, b)
+				var c, e Element
+				c.Mul(b, res[1])
+				e.Mul(a, res[2])
+				e.Add(&c, &e)
+				return e.Equal(res[0])
+			},
+			genE,
+			genE,
+		))
+
+		properties.TestingRun(t, gopter.ConsoleReporter(false))
+	}
+}
+
+func TestZZDMatchesZZ2(t *testing.T) {
+
+	t.Parallel()
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+
+	properties := gopter.NewProperties(parameters)
+
+	ring, _ := NewRing(2)
+	genE := GenElement(ring, boundSize)
+
+	properties.Property("mul and norm should match zz2 for d=2", prop.ForAll(
+		func(a, b *Element) bool {
+			x := zz2.ComplexNumber{A0: a.A0, A1: a.A1}
+			y := zz2.ComplexNumber{A0: b.A0, A1: b.A1}
+			var c Element
+			var z zz2.ComplexNumber
+			c.Mul(a, b)
+			z.Mul(&x, &y)
+			return c.A0.Cmp(z.A0) == 0 && c.A1.Cmp(z.A1) == 0 &&
+				a.Norm().Cmp(x.Norm()) == 0
+		},
+		genE,
+		genE,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// GenNumber generates a random signed integer
+func GenNumber(boundSize int64) gopter.Gen {
+	return func(genParams *gopter.GenParameters) *gopter.GenResult {
+		var bound big.Int
+		bound.Exp(big.NewInt(2), big.NewInt(boundSize), nil)
+		elmt, _ := rand.Int(genParams.Rng, &bound)
+		if genParams.NextBool() {
+			elmt.Neg(elmt)
+		}
+		genResult := gopter.NewGenResult(elmt, gopter.NoShrinker)
+		return genResult
+	}
+}
+
+// GenElement generates a random non-zero element of ring
+func GenElement(ring *Ring, boundSize int64) gopter.Gen {
+	return gopter.CombineGens(
+		GenNumber(boundSize),
+		GenNumber(boundSize),
+	).Map(func(values []interface{}) *Element {
+		a0, a1 := values[0].(*big.Int), values[1].(*big.Int)
+		if a0.Sign() == 0 && a1.Sign() == 0 {
+			a0.SetUint64(1)
+		}
+		return ring.NewElement(a0, a1)
+	})
+}
+
+// bench
+var benchRes [3]*Element
+
+func BenchmarkHalfGCD(b *testing.B) {
+	var n, _ = new(big.Int).SetString("100000000000000000000000000000000", 16) // 2^128
+	for _, d := range euclideanD {
+		ring, _ := NewRing(d)
+		a0, _ := rand.Int(rand.Reader, n)
+		a1, _ := rand.Int(rand.Reader, n)
+		c0, _ := rand.Int(rand.Reader, n)
+		c1, _ := rand.Int(rand.Reader, n)
+		a := ring.NewElement(a0, a1)
+		c := ring.NewElement(c0, c1)
+		b.Run("d="+big.NewInt(d).String(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				benchRes = HalfGCD(a, c)
+			}
+		})
+	}
+}